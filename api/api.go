@@ -54,6 +54,12 @@ type Operations struct {
 	Operations bench.Operations `json:"operations"`
 }
 
+// ServerStats contains samples of the DS3 server state taken during the benchmark.
+type ServerStats struct {
+	Cache bench.CacheSamples `json:"cache,omitempty"`
+	Jobs  bench.JobSamples   `json:"jobs,omitempty"`
+}
+
 // Server contains the state of the running server.
 type Server struct {
 	status  BenchmarkStatus
@@ -62,6 +68,8 @@ type Server struct {
 	aggrDur time.Duration
 	server  *http.Server
 	cmdLine string
+	stats   ServerStats
+	live    bench.OpsSummaries
 
 	// Shutting down
 	ctx    context.Context
@@ -84,6 +92,34 @@ func (s *Server) OperationsReady(ops bench.Operations, filename, cmdLine string)
 	s.mu.Unlock()
 }
 
+// AddCacheSample adds a cache state sample to the server stats.
+func (s *Server) AddCacheSample(c bench.CacheSample) {
+	s.mu.Lock()
+	s.stats.Cache = append(s.stats.Cache, c)
+	s.mu.Unlock()
+}
+
+// AddJobSample adds an active jobs sample to the server stats.
+func (s *Server) AddJobSample(j bench.JobSample) {
+	s.mu.Lock()
+	s.stats.Jobs = append(s.stats.Jobs, j)
+	s.mu.Unlock()
+}
+
+// maxLiveOps is the number of live operation summaries kept.
+const maxLiveOps = 100000
+
+// AddLiveOps adds summaries of operations reported while the benchmark runs.
+// Only the most recent summaries are kept.
+func (s *Server) AddLiveOps(ops bench.OpsSummaries) {
+	s.mu.Lock()
+	s.live = append(s.live, ops...)
+	if drop := len(s.live) - maxLiveOps; drop > 0 {
+		s.live = append(s.live[:0], s.live[drop:]...)
+	}
+	s.mu.Unlock()
+}
+
 // SetLnLoggers can be used to set upstream loggers.
 // When logging to the servers these will be called.
 func (s *Server) SetLnLoggers(info, err func(data ...interface{})) {
@@ -150,6 +186,61 @@ func (s *Server) handleStatus(w http.ResponseWriter, req *http.Request) {
 	w.Write(b)
 }
 
+// handleServerStats handles GET `/v1/server-stats` requests.
+// Samples taken so far are returned, so it can be polled while the benchmark runs.
+func (s *Server) handleServerStats(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	b, err := json.MarshalIndent(s.stats, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
+// handleLive handles GET `/v1/live` requests with optional "since" parameter.
+// Summaries of operations reported by clients while the benchmark runs are returned.
+// If "since" is set, only summaries ending after that time (RFC3339) are returned.
+func (s *Server) handleLive(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var since time.Time
+	if p := req.URL.Query().Get("since"); p != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339Nano, p)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+	}
+	s.mu.Lock()
+	live := make(bench.OpsSummaries, 0, len(s.live))
+	for _, sum := range s.live {
+		if sum.To.After(since) {
+			live = append(live, sum)
+		}
+	}
+	s.mu.Unlock()
+	b, err := json.MarshalIndent(live, "", "  ")
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.Write(b)
+}
+
 // handleAggregated handles GET `/v1/aggregated` requests with optional "segment" parameter.
 func (s *Server) handleAggregated(w http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodGet {
@@ -269,6 +360,8 @@ func NewBenchmarkMonitor(listenAddr string) *Server {
 	mux.HandleFunc("/v1/stop", s.handleStop)
 	mux.HandleFunc("/v1/status", s.handleStatus)
 	mux.HandleFunc("/v1/aggregated", s.handleAggregated)
+	mux.HandleFunc("/v1/server-stats", s.handleServerStats)
+	mux.HandleFunc("/v1/live", s.handleLive)
 	mux.HandleFunc("/v1/operations/json", s.handleDownloadJSON)
 	mux.HandleFunc("/v1/operations", s.handleDownloadZst)
 
@@ -140,7 +140,14 @@ func mainAnalyze(ctx *cli.Context) error {
 		ops, err := bench.OperationsFromCSV(zstdDec, true, ctx.Int("analyze.offset"), ctx.Int("analyze.limit"), log)
 		fatalIf(probe.NewError(err), "Unable to parse input")
 
-		printAnalysis(ctx, ops)
+		var samples serverSamples
+		if arg != "-" {
+			base := strings.TrimSuffix(arg, ".csv.zst")
+			samples.cache = readCacheSamples(zstdDec, base+cacheSamplesSuffix)
+			samples.jobs = readJobSamples(zstdDec, base+jobSamplesSuffix)
+		}
+
+		printAnalysis(ctx, ops, samples)
 		monitor.OperationsReady(ops, strings.TrimSuffix(filepath.Base(arg), ".csv.zst"), commandLine(ctx))
 	}
 	return nil
@@ -234,7 +241,37 @@ func printMixedOpAnalysis(ctx *cli.Context, aggr aggregate.Aggregated, details b
 	}
 }
 
-func printAnalysis(ctx *cli.Context, o bench.Operations) {
+// readCacheSamples reads cache state samples from fileName if it exists.
+func readCacheSamples(dec *zstd.Decoder, fileName string) bench.CacheSamples {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	err = dec.Reset(f)
+	fatalIf(probe.NewError(err), "Unable to read cache samples")
+	cache, err := bench.CacheSamplesFromCSV(dec)
+	fatalIf(probe.NewError(err), "Unable to parse cache samples")
+	return cache
+}
+
+// readJobSamples reads active job samples from fileName if it exists.
+func readJobSamples(dec *zstd.Decoder, fileName string) bench.JobSamples {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	err = dec.Reset(f)
+	fatalIf(probe.NewError(err), "Unable to read job samples")
+	jobs, err := bench.JobSamplesFromCSV(dec)
+	fatalIf(probe.NewError(err), "Unable to parse job samples")
+	return jobs
+}
+
+// printAnalysis prints the analysis of o.
+// If server state samples are given they are printed with the throughput segments.
+func printAnalysis(ctx *cli.Context, o bench.Operations, samples serverSamples) {
 	details := ctx.Bool("analyze.v")
 	var wrSegs io.Writer
 	prefiltered := false
@@ -255,7 +292,7 @@ func printAnalysis(ctx *cli.Context, o bench.Operations) {
 			hosts := o.Endpoints()
 			console.Println("Host not found, valid hosts are:")
 			for _, h := range hosts {
-				console.Println("\t* %s", h)
+				console.Printf("\t* %s\n", h)
 			}
 			return
 		}
@@ -264,7 +301,8 @@ func printAnalysis(ctx *cli.Context, o bench.Operations) {
 	}
 
 	if wantOp := ctx.String("analyze.op"); wantOp != "" {
-		prefiltered = prefiltered || o.IsMixed()
+		// Job phases overlap the operations covering whole jobs.
+		prefiltered = prefiltered || o.FilterByJobPhase(false).IsMixed()
 		o = o.FilterByOp(wantOp)
 	}
 	durFn := func(total time.Duration) time.Duration {
@@ -294,8 +332,12 @@ func printAnalysis(ctx *cli.Context, o bench.Operations) {
 		return
 	}
 
+	defer printJobPhases(aggr)
 	if aggr.Mixed {
 		printMixedOpAnalysis(ctx, aggr, details)
+		if aggr.MixedServerStats != nil {
+			printSampleSegments(aggr.MixedServerStats.Segmented, samples, details)
+		}
 		return
 	}
 
@@ -397,6 +439,60 @@ func printAnalysis(ctx *cli.Context, o bench.Operations) {
 		console.Println(" * Fastest:", aggregate.SegmentSmall{BPS: segs.FastestBPS, OPS: segs.FastestOPS, Start: segs.FastestStart}.StringLong(dur, details))
 		console.Println(" * 50% Median:", aggregate.SegmentSmall{BPS: segs.MedianBPS, OPS: segs.MedianOPS, Start: segs.MedianStart}.StringLong(dur, details))
 		console.Println(" * Slowest:", aggregate.SegmentSmall{BPS: segs.SlowestBPS, OPS: segs.SlowestOPS, Start: segs.SlowestStart}.StringLong(dur, details))
+		printSampleSegments(segs, samples, details)
+	}
+}
+
+// serverSamples holds the server state sampled during a benchmark.
+type serverSamples struct {
+	cache bench.CacheSamples
+	jobs  bench.JobSamples
+}
+
+// printSampleSegments prints the sampled server state during the fastest, median and slowest segment.
+// With details the state during every segment is printed.
+func printSampleSegments(segs *aggregate.ThroughputSegmented, samples serverSamples, details bool) {
+	if segs == nil {
+		return
+	}
+	if len(samples.cache) > 0 {
+		printSegmentStates(segs, "Cache state", "cache", func(t time.Time) (fmt.Stringer, bool) {
+			return samples.cache.At(t)
+		}, details)
+	}
+	if len(samples.jobs) > 0 {
+		printSegmentStates(segs, "Job state", "jobs", func(t time.Time) (fmt.Stringer, bool) {
+			return samples.jobs.At(t)
+		}, details)
+	}
+}
+
+// printSegmentStates prints the state returned by at for the fastest, median and slowest segment.
+// With details the state during every segment is printed.
+func printSegmentStates(segs *aggregate.ThroughputSegmented, title, short string, at func(t time.Time) (fmt.Stringer, bool), details bool) {
+	dur := time.Millisecond * time.Duration(segs.SegmentDurationMillis)
+	state := func(start time.Time) string {
+		// Use the state at the end of the segment.
+		if s, ok := at(start.Add(dur)); ok {
+			return s.String()
+		}
+		return "no sample"
+	}
+	console.SetColor("Print", color.New(color.FgHiWhite))
+	console.Println("\n" + title + ":")
+	console.SetColor("Print", color.New(color.FgWhite))
+	console.Println(" * Fastest:", state(segs.FastestStart))
+	console.Println(" * 50% Median:", state(segs.MedianStart))
+	console.Println(" * Slowest:", state(segs.SlowestStart))
+	if !details {
+		return
+	}
+	bySegment := append([]aggregate.SegmentSmall(nil), segs.Segments...)
+	sort.Slice(bySegment, func(i, j int) bool {
+		return bySegment[i].Start.Before(bySegment[j].Start)
+	})
+	for _, seg := range bySegment {
+		console.Printf(" * %s: %s, %s %s\n", seg.Start.Format("15:04:05"), aggregate.BPSorOPS(seg.BPS, seg.OPS), short, state(seg.Start))
 	}
 }
 
@@ -481,6 +577,12 @@ func printRequestAnalysis(ctx *cli.Context, ops aggregate.Operation, details boo
 		if reqs.FirstByte != nil {
 			console.Println(" * TTFB:", reqs.FirstByte)
 		}
+		if reqs.CacheWaitAvgMillis > 0 {
+			console.Println(" * Cache wait:", cacheWaitString(reqs.CacheWaitAvgMillis, reqs.DurAvgMillis))
+		}
+		if reqs.PersistAvgMillis > 0 {
+			console.Println(" * Persist:", persistString(reqs.IngestAvgMillis, reqs.PersistAvgMillis))
+		}
 
 		if details && reqs.FirstAccess != nil {
 			reqs := reqs.FirstAccess
@@ -569,6 +671,12 @@ func printRequestAnalysis(ctx *cli.Context, ops aggregate.Operation, details boo
 		if s.FirstByte != nil {
 			console.Println(" * TTFB:", s.FirstByte)
 		}
+		if s.CacheWaitAvgMillis > 0 {
+			console.Println(" * Cache wait:", cacheWaitString(s.CacheWaitAvgMillis, s.AvgDurationMillis))
+		}
+		if s.PersistAvgMillis > 0 {
+			console.Println(" * Persist:", persistString(s.IngestAvgMillis, s.PersistAvgMillis))
+		}
 
 		if s.FirstAccess != nil {
 			s := s.FirstAccess
@@ -609,6 +717,40 @@ func printRequestAnalysis(ctx *cli.Context, ops aggregate.Operation, details boo
 	}
 }
 
+// printJobPhases prints the breakdown of bulk job time by phase, if recorded.
+func printJobPhases(aggr aggregate.Aggregated) {
+	if len(aggr.JobPhases) == 0 {
+		return
+	}
+	console.Println("\n----------------------------------------")
+	console.SetColor("Print", color.New(color.FgHiWhite))
+	console.Println("Bulk job phases:")
+	console.SetColor("Print", color.New(color.FgWhite))
+	for _, p := range aggr.JobPhases {
+		console.Printf(" * %s: %v per job (%.1f%% of job time). Operations: %d.", p.Type, time.Duration(p.PerJobMillis)*time.Millisecond, 100*p.Share, p.N)
+		if p.Errors > 0 {
+			console.Printf(" Errors: %d.", p.Errors)
+		}
+		console.Println("")
+	}
+}
+
+// cacheWaitString returns the average cache wait and its share of the average request time.
+func cacheWaitString(waitMillis, durMillis int) string {
+	wait := time.Duration(waitMillis) * time.Millisecond
+	if durMillis <= 0 {
+		return fmt.Sprint("Avg: ", wait)
+	}
+	return fmt.Sprintf("Avg: %v (%.1f%% of request time), Transferring: %v", wait, 100*float64(waitMillis)/float64(durMillis), time.Duration(durMillis-waitMillis)*time.Millisecond)
+}
+
+// persistString returns the average ingest and persist latency.
+func persistString(ingestMillis, persistMillis int) string {
+	ingest := time.Duration(ingestMillis) * time.Millisecond
+	persist := time.Duration(persistMillis) * time.Millisecond
+	return fmt.Sprintf("Ingest avg: %v, Persist avg: %v (%v after ingest)", ingest, persist, persist-ingest)
+}
+
 // analysisDur returns the analysis duration or 0 if un-parsable.
 func analysisDur(ctx *cli.Context, total time.Duration) time.Duration {
 	dur := ctx.String("analyze.dur")
@@ -19,6 +19,8 @@ package cli
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
 	"errors"
 	"flag"
 	"fmt"
@@ -43,10 +45,12 @@ const (
 
 // clientReply contains the response to a server request.
 type clientReply struct {
-	Type      clientReplyType  `json:"type"`
-	Time      time.Time        `json:"time"`
-	Err       string           `json:"err,omitempty"`
-	Ops       bench.Operations `json:"ops,omitempty"`
+	Type clientReplyType  `json:"type"`
+	Time time.Time        `json:"time"`
+	Err  string           `json:"err,omitempty"`
+	Ops  bench.Operations `json:"ops,omitempty"`
+	// Summaries of operations recorded since the last acknowledged status.
+	Live      []bench.LiveBatch `json:"live,omitempty"`
 	StageInfo struct {
 		Started  bool              `json:"started"`
 		Finished bool              `json:"finished"`
@@ -113,8 +117,25 @@ var wsUpgrader = websocket.Upgrader{
 	},
 }
 
+// checkSecret returns whether the request carries the secret.
+// Any request is accepted if secret is empty.
+func checkSecret(r *http.Request, secret string) bool {
+	if secret == "" {
+		return true
+	}
+	// Compare hashes, so the time taken doesn't depend on the secret.
+	want, got := sha256.Sum256([]byte(secret)), sha256.Sum256([]byte(r.Header.Get(warpSecretHeader)))
+	return subtle.ConstantTimeCompare(want[:], got[:]) == 1
+}
+
 // serveWs handles incoming requests.
-func serveWs(w http.ResponseWriter, r *http.Request) {
+// If secret is set, servers must present it to connect.
+func serveWs(w http.ResponseWriter, r *http.Request, secret string) {
+	if !checkSecret(r, secret) {
+		console.Errorf("Rejecting server %v: invalid secret\n", r.RemoteAddr)
+		http.Error(w, "invalid secret", http.StatusUnauthorized)
+		return
+	}
 	ws, err := wsUpgrader.Upgrade(w, r, nil)
 	if err != nil {
 		console.Error("upgrade:", err.Error())
@@ -132,6 +153,7 @@ func serveWs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err = s.validate(); err != nil {
+		console.Errorf("Rejecting server %v: %v\n", ws.RemoteAddr(), err)
 		ws.WriteJSON(clientReply{Err: err.Error()})
 		return
 	}
@@ -249,7 +271,9 @@ func serveWs(w http.ResponseWriter, r *http.Request) {
 			ab.Lock()
 			err := ab.err
 			stageInfo := ab.info
+			live := ab.live
 			ab.Unlock()
+			resp.Live = live.Take(req.LiveAck)
 			if err != nil {
 				resp.Err = err.Error()
 				break
@@ -270,6 +294,22 @@ func serveWs(w http.ResponseWriter, r *http.Request) {
 				resp.StageInfo.Custom = info.custom
 			default:
 			}
+		case serverReqStopBench:
+			activeBenchmarkMu.Lock()
+			ab := activeBenchmark
+			activeBenchmarkMu.Unlock()
+			if ab == nil {
+				resp.Err = "no benchmark running"
+				break
+			}
+			ab.Lock()
+			stop := ab.stopBench
+			ab.Unlock()
+			if stop != nil {
+				console.Infoln("Stopping benchmark as requested by server")
+				stop()
+			}
+			resp.Type = clientRespStatus
 		case serverReqSendOps:
 			activeBenchmarkMu.Lock()
 			ab := activeBenchmark
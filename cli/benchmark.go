@@ -83,12 +83,45 @@ var benchFlags = []cli.Flag{
 		Usage: "Specify a benchmark start time. Time format is 'hh:mm' where hours are specified in 24h format, server TZ.",
 		Value: "",
 	},
+	cli.DurationFlag{
+		Name:  "cache.sample",
+		Usage: "Sample the BlackPearl cache state at this interval during the benchmark and save it next to the benchmark data. Not used with warp-client.",
+		Value: 0,
+	},
+	cli.DurationFlag{
+		Name:  "jobs.sample",
+		Usage: "Sample the active jobs on the BlackPearl at this interval during the benchmark and save it next to the benchmark data. Not used with warp-client.",
+		Value: 0,
+	},
+	cli.IntFlag{
+		Name:  "jobs.sample-chunks",
+		Usage: "Count the chunks of up to this many active jobs per jobs.sample. Each job costs one extra request to the BlackPearl per sample.",
+		Value: 10,
+	},
 	cli.StringFlag{
 		Name:   "warp-client",
 		Usage:  "Connect to warp clients and run benchmarks there.",
 		EnvVar: "",
 		Value:  "",
 	},
+	cli.DurationFlag{
+		Name:  "warp-client.reconnect",
+		Usage: "Keep trying to reconnect to a warp client that dropped during a stage for this long. Clients keep their operations until reconnected.",
+		Value: time.Minute,
+	},
+	cli.StringFlag{
+		Name:   "warp-client.secret",
+		Usage:  "Shared secret to present to warp clients started with --secret.",
+		EnvVar: appNameUC + "_CLIENT_SECRET",
+	},
+	cli.BoolFlag{
+		Name:  "warp-client.tls",
+		Usage: "Connect to warp clients using TLS.",
+	},
+	cli.StringFlag{
+		Name:  "warp-client.ca-cert",
+		Usage: "Trust the CA certificates in this PEM file in addition to the system CAs when connecting to warp clients.",
+	},
 }
 
 // runBench will run the supplied benchmark and save/print the analysis.
@@ -111,54 +144,16 @@ func runBench(ctx *cli.Context, b bench.Benchmark) error {
 	defer monitor.Done()
 
 	monitor.InfoLn("Preparing server.")
-	pgDone := make(chan struct{})
 	c := b.GetCommon()
 	c.Clear = !ctx.Bool("noclear")
 	if ctx.Bool("autoterm") {
-		// TODO: autoterm cannot be used when in client/server mode
 		c.AutoTermDur = ctx.Duration("autoterm.dur")
 		c.AutoTermScale = ctx.Float64("autoterm.pct") / 100
 	}
+	var pgDone <-chan struct{}
 	if !globalQuiet && !globalJSON {
 		c.PrepareProgress = make(chan float64, 1)
-		const pgScale = 10000
-		pg := newProgressBar(pgScale, pb.U_NO)
-		pg.ShowCounters = false
-		pg.ShowElapsedTime = false
-		pg.ShowSpeed = false
-		pg.ShowTimeLeft = false
-		pg.ShowFinalTime = true
-		go func() {
-			defer close(pgDone)
-			defer pg.Finish()
-			tick := time.NewTicker(time.Millisecond * 125)
-			defer tick.Stop()
-			pg.Set(-1)
-			pg.SetCaption("Preparing: ")
-			newVal := int64(-1)
-			for {
-				select {
-				case <-tick.C:
-					current := pg.Get()
-					if current != newVal {
-						pg.Set64(newVal)
-						pg.Update()
-					}
-					monitor.InfoQuietln(fmt.Sprintf("Preparation: %0.0f%% done...", float64(newVal)/float64(100)))
-				case pct, ok := <-c.PrepareProgress:
-					if !ok {
-						pg.Set64(pgScale)
-						if newVal > 0 {
-							pg.Update()
-						}
-						return
-					}
-					newVal = int64(pct * pgScale)
-				}
-			}
-		}()
-	} else {
-		close(pgDone)
+		pgDone = stageProgress(monitor, "Preparing: ", "Preparation", c.PrepareProgress)
 	}
 
 	err := b.Prepare(context.Background())
@@ -205,11 +200,11 @@ func runBench(ctx *cli.Context, b bench.Benchmark) error {
 	prof, err := startProfiling(ctx2, ctx)
 	fatalIf(probe.NewError(err), "Unable to start profile.")
 	monitor.InfoLn("Starting benchmark in ", time.Until(tStart).Round(time.Second), "...")
-	pgDone = make(chan struct{})
+	benchDone := make(chan struct{})
 	if !globalQuiet && !globalJSON {
 		pg := newProgressBar(int64(benchDur), pb.U_DURATION)
 		go func() {
-			defer close(pgDone)
+			defer close(benchDone)
 			defer pg.Finish()
 			pg.SetCaption("Benchmarking:")
 			tick := time.NewTicker(time.Millisecond * 125)
@@ -233,11 +228,33 @@ func runBench(ctx *cli.Context, b bench.Benchmark) error {
 			}
 		}()
 	} else {
-		close(pgDone)
+		close(benchDone)
+	}
+	var cacheSampler chan bench.CacheSamples
+	if d := ctx.Duration("cache.sample"); d > 0 {
+		cacheSampler = make(chan bench.CacheSamples, 1)
+		go func() {
+			cacheSampler <- bench.SampleCache(ctx2, c.Client, d, monitor.AddCacheSample)
+		}()
+	}
+	var jobSampler chan bench.JobSamples
+	if d := ctx.Duration("jobs.sample"); d > 0 {
+		jobSampler = make(chan bench.JobSamples, 1)
+		go func() {
+			jobSampler <- bench.SampleJobs(ctx2, c.Client, d, ctx.Int("jobs.sample-chunks"), monitor.AddJobSample)
+		}()
 	}
 	ops, _ := b.Start(ctx2, start)
 	cancel()
-	<-pgDone
+	<-benchDone
+	var cache bench.CacheSamples
+	if cacheSampler != nil {
+		cache = <-cacheSampler
+	}
+	var jobs bench.JobSamples
+	if jobSampler != nil {
+		jobs = <-jobSampler
+	}
 
 	// Previous context is canceled, create a new...
 	monitor.InfoLn("Saving benchmark data...")
@@ -262,16 +279,100 @@ func runBench(ctx *cli.Context, b bench.Benchmark) error {
 			monitor.InfoLn(fmt.Sprintf("Benchmark data written to %q\n", fileName+".csv.zst"))
 		}()
 	}
+	if len(cache) > 0 {
+		writeSamples(monitor, fileName+cacheSamplesSuffix, "Cache samples", cache.CSV)
+	}
+	if len(jobs) > 0 {
+		writeSamples(monitor, fileName+jobSamplesSuffix, "Job samples", jobs.CSV)
+	}
 	monitor.OperationsReady(ops, fileName, commandLine(ctx))
-	printAnalysis(ctx, ops)
+	printAnalysis(ctx, ops, serverSamples{cache: cache, jobs: jobs})
 	if !ctx.Bool("keep-data") && !ctx.Bool("noclear") {
 		monitor.InfoLn("Starting cleanup...")
+		var pgDone <-chan struct{}
+		if !globalQuiet && !globalJSON {
+			c.CleanupProgress = make(chan float64, 1)
+			pgDone = stageProgress(monitor, "Cleaning up: ", "Cleanup", c.CleanupProgress)
+		}
 		b.Cleanup(context.Background())
+		if c.CleanupProgress != nil {
+			close(c.CleanupProgress)
+			<-pgDone
+		}
 	}
 	monitor.InfoLn("Cleanup Done.")
 	return nil
 }
 
+// cacheSamplesSuffix and jobSamplesSuffix are appended to the benchmark
+// data file name to get the names of the server state sample files.
+const (
+	cacheSamplesSuffix = ".cache.csv.zst"
+	jobSamplesSuffix   = ".jobs.csv.zst"
+)
+
+// writeSamples writes server state samples to a compressed CSV file.
+func writeSamples(monitor *api.Server, fileName, what string, writeCSV func(w io.Writer) error) {
+	f, err := os.Create(fileName)
+	if err != nil {
+		monitor.Errorln("Unable to write", strings.ToLower(what)+":", err)
+		return
+	}
+	defer f.Close()
+	enc, err := zstd.NewWriter(f, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	fatalIf(probe.NewError(err), "Unable to compress "+strings.ToLower(what))
+	defer enc.Close()
+	if err := writeCSV(enc); err != nil {
+		monitor.Errorln("Unable to write", strings.ToLower(what)+":", err)
+		return
+	}
+	monitor.InfoLn(fmt.Sprintf("%s written to %q\n", what, fileName))
+}
+
+// stageProgress shows a progress bar for a benchmark stage, updated from progress
+// in the range 0 -> 1 until it is closed.
+// The returned channel is closed when the progress bar has finished.
+func stageProgress(monitor *api.Server, caption, stage string, progress <-chan float64) <-chan struct{} {
+	pgDone := make(chan struct{})
+	const pgScale = 10000
+	pg := newProgressBar(pgScale, pb.U_NO)
+	pg.ShowCounters = false
+	pg.ShowElapsedTime = false
+	pg.ShowSpeed = false
+	pg.ShowTimeLeft = false
+	pg.ShowFinalTime = true
+	go func() {
+		defer close(pgDone)
+		defer pg.Finish()
+		tick := time.NewTicker(time.Millisecond * 125)
+		defer tick.Stop()
+		pg.Set(-1)
+		pg.SetCaption(caption)
+		newVal := int64(-1)
+		for {
+			select {
+			case <-tick.C:
+				current := pg.Get()
+				if current != newVal {
+					pg.Set64(newVal)
+					pg.Update()
+				}
+				monitor.InfoQuietln(fmt.Sprintf("%s: %0.0f%% done...", stage, float64(newVal)/float64(100)))
+			case pct, ok := <-progress:
+				if !ok {
+					pg.Set64(pgScale)
+					if newVal > 0 {
+						pg.Update()
+					}
+					return
+				}
+				newVal = int64(pct * pgScale)
+			}
+		}
+	}()
+	return pgDone
+}
+
 var (
 	activeBenchmarkMu sync.Mutex
 	activeBenchmark   *clientBenchmark
@@ -281,7 +382,9 @@ type clientBenchmark struct {
 	sync.Mutex
 	ctx       context.Context
 	cancel    context.CancelFunc
+	stopBench context.CancelFunc
 	results   bench.Operations
+	live      *bench.LiveOps
 	err       error
 	stage     benchmarkStage
 	info      map[benchmarkStage]stageInfo
@@ -297,6 +400,7 @@ type stageInfo struct {
 
 func (c *clientBenchmark) init(ctx context.Context) {
 	c.results = nil
+	c.live = &bench.LiveOps{}
 	c.err = nil
 	c.stage = stageNotStarted
 	c.info = make(map[benchmarkStage]stageInfo, len(benchmarkStages))
@@ -376,6 +480,10 @@ func runClientBenchmark(ctx *cli.Context, b bench.Benchmark, cb *clientBenchmark
 	start := cb.info[stageBenchmark].start
 	ctx2, cancel := context.WithCancel(cb.ctx)
 	defer cancel()
+	common.Live = cb.live
+	// Disconnects and new benchmarks cancel cb.ctx.
+	common.Abort = cb.ctx
+	cb.stopBench = cancel
 	cb.Unlock()
 	err = b.Prepare(ctx2)
 
@@ -415,6 +523,10 @@ func runClientBenchmark(ctx *cli.Context, b bench.Benchmark, cb *clientBenchmark
 	}
 
 	ops, err := b.Start(ctx2, start)
+	ops.SetClientID(cID)
+	ops.SortByStartTime()
+	// Keep the operations until the server downloads them,
+	// even if the benchmark failed.
 	cb.Lock()
 	cb.results = ops
 	cb.Unlock()
@@ -422,8 +534,6 @@ func runClientBenchmark(ctx *cli.Context, b bench.Benchmark, cb *clientBenchmark
 	if err != nil {
 		return err
 	}
-	ops.SetClientID(cID)
-	ops.SortByStartTime()
 
 	f, err := os.Create(fileName + ".csv.zst")
 	if err != nil {
@@ -535,8 +645,16 @@ func checkBenchmark(ctx *cli.Context) {
 			fatalIf(errDummy(), "syncstart is in the past: %v", t)
 		}
 	}
+	if ctx.Duration("cache.sample") < 0 {
+		fatalIf(errDummy(), "cache.sample cannot be negative")
+	}
+	if ctx.Duration("jobs.sample") < 0 {
+		fatalIf(errDummy(), "jobs.sample cannot be negative")
+	}
+	if ctx.Int("jobs.sample-chunks") < 0 {
+		fatalIf(errDummy(), "jobs.sample-chunks cannot be negative")
+	}
 	if ctx.Bool("autoterm") {
-		// TODO: autoterm cannot be used when in client/server mode
 		if ctx.Duration("autoterm.dur") <= 0 {
 			fatalIf(errDummy(), "autoterm.dur cannot be zero or negative")
 		}
@@ -19,15 +19,19 @@ package cli
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/cheggaaa/pb"
 	"github.com/joshcarter/warp-ds3/api"
 	"github.com/joshcarter/warp-ds3/pkg/bench"
 	"github.com/klauspost/compress/zstd"
@@ -36,7 +40,10 @@ import (
 	"github.com/minio/websocket"
 )
 
-const warpServerVersion = 1
+const warpServerVersion = 2
+
+// warpSecretHeader carries the shared secret when a server connects to a client.
+const warpSecretHeader = "X-Warp-Secret"
 
 type serverRequestOp string
 
@@ -46,13 +53,13 @@ const (
 	serverReqStartStage  serverRequestOp = "start_stage"
 	serverReqStageStatus serverRequestOp = "stage_status"
 	serverReqSendOps     serverRequestOp = "send_ops"
+	serverReqStopBench   serverRequestOp = "stop_benchmark"
 )
 
 const serverFlagName = "serve"
 
 type serverInfo struct {
 	ID        string `json:"id"`
-	Secret    string `json:"secret"`
 	Version   int    `json:"version"`
 	connected bool
 }
@@ -83,6 +90,8 @@ type serverRequest struct {
 	Stage     benchmarkStage `json:"stage"`
 	StartTime time.Time      `json:"start_time"`
 	ClientIdx int            `json:"client_idx"`
+	// LiveAck acknowledges the live summary batches received so far.
+	LiveAck uint64 `json:"live_ack,omitempty"`
 }
 
 // runServerBenchmark will run a benchmark server if requested.
@@ -92,30 +101,56 @@ func runServerBenchmark(ctx *cli.Context, b bench.Benchmark) (bool, error) {
 		return false, nil
 	}
 
-	conns := newConnections(parseHosts(ctx.String("warp-client"), false))
+	conns := newConnections(parseHosts(ctx.String("warp-client"), false), ctx.String("warp-client.secret"))
+	if ctx.Bool("warp-client.tls") {
+		conns.tlsConfig = &tls.Config{
+			RootCAs:    mustGetSystemCertPool(),
+			MinVersion: tls.VersionTLS12,
+		}
+		if caFile := ctx.String("warp-client.ca-cert"); caFile != "" {
+			pem, err := os.ReadFile(caFile)
+			if err != nil {
+				return true, err
+			}
+			if !conns.tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return true, fmt.Errorf("no CA certificates found in %s", caFile)
+			}
+		}
+	}
 	if len(conns.hosts) == 0 {
 		return true, errors.New("no hosts")
 	}
 	conns.info = printInfo
 	conns.errLn = printError
+	conns.reconnect = ctx.Duration("warp-client.reconnect")
+	// Auto termination needs all summaries.
+	live := liveOps{keepAll: ctx.Bool("autoterm")}
 	defer conns.closeAll()
 	monitor := api.NewBenchmarkMonitor(ctx.String(serverFlagName))
 	defer monitor.Done()
 	monitor.SetLnLoggers(printInfo, printError)
 	infoLn := monitor.InfoLn
 	errorLn := monitor.Errorln
+	conns.live = func(ops bench.OpsSummaries) {
+		live.add(ops)
+		monitor.AddLiveOps(ops)
+	}
 
 	var allOps bench.Operations
 
 	// Serialize parameters
 	excludeFlags := map[string]struct{}{
-		"warp-client":        {},
-		"warp-client-server": {},
-		"serverprof":         {},
-		"autocompletion":     {},
-		"help":               {},
-		"syncstart":          {},
-		"analyze.out":        {},
+		"warp-client":           {},
+		"warp-client-server":    {},
+		"warp-client.secret":    {},
+		"warp-client.tls":       {},
+		"warp-client.ca-cert":   {},
+		"warp-client.reconnect": {},
+		"serverprof":            {},
+		"autocompletion":        {},
+		"help":                  {},
+		"syncstart":             {},
+		"analyze.out":           {},
 	}
 	req := serverRequest{
 		Operation: serverReqBenchmark,
@@ -171,12 +206,23 @@ func runServerBenchmark(ctx *cli.Context, b bench.Benchmark) (bool, error) {
 	if err != nil {
 		return true, err
 	}
-	err = conns.startStageAll(stageBenchmark, time.Now().Add(benchmarkWait), false)
+	benchStart := time.Now().Add(benchmarkWait)
+	err = conns.startStageAll(stageBenchmark, benchStart, false)
 	if err != nil {
 		errorLn("Failed to start all clients", err)
 	}
 	infoLn("Running benchmark on all clients...")
+	stopProgress := live.progress(monitor, benchStart, ctx.Duration("duration"))
+	stopAutoTerm := func() {}
+	if ctx.Bool("autoterm") {
+		stopAutoTerm = live.autoTerm(ctx.Float64("autoterm.pct")/100, ctx.Duration("autoterm.dur"), func(status string) {
+			infoLn(status)
+			conns.stopBenchmark()
+		})
+	}
 	err = conns.waitForStage(stageBenchmark, false, common)
+	stopAutoTerm()
+	stopProgress()
 	if err != nil {
 		errorLn("Failed to keep connection to all clients", err)
 	}
@@ -189,17 +235,25 @@ func runServerBenchmark(ctx *cli.Context, b bench.Benchmark) (bool, error) {
 
 	infoLn("Done. Downloading operations...")
 	downloaded := conns.downloadOps()
+	partial := conns.partialClients(downloaded)
+	for _, txt := range partial {
+		errorLn(txt)
+	}
 	switch len(downloaded) {
 	case 0:
 	case 1:
-		allOps = downloaded[0]
+		allOps = downloaded[0].ops
 	default:
 		threads := uint16(0)
-		for _, ops := range downloaded {
-			threads = ops.OffsetThreads(threads)
-			allOps = append(allOps, ops...)
+		for _, d := range downloaded {
+			threads = d.ops.OffsetThreads(threads)
+			allOps = append(allOps, d.ops...)
 		}
 	}
+	comment := commandLine(ctx)
+	if len(partial) > 0 {
+		comment += "\n" + strings.Join(partial, "\n")
+	}
 
 	allOps.SortByStartTime()
 	f, err := os.Create(fileName + ".csv.zst")
@@ -212,14 +266,14 @@ func runServerBenchmark(ctx *cli.Context, b bench.Benchmark) (bool, error) {
 			fatalIf(probe.NewError(err), "Unable to compress benchmark output")
 
 			defer enc.Close()
-			err = allOps.CSV(enc, commandLine(ctx))
+			err = allOps.CSV(enc, comment)
 			fatalIf(probe.NewError(err), "Unable to write benchmark output")
 
 			infoLn(fmt.Sprintf("Benchmark data written to %q\n", fileName+".csv.zst"))
 		}()
 	}
 	monitor.OperationsReady(allOps, fileName, commandLine(ctx))
-	printAnalysis(ctx, allOps)
+	printAnalysis(ctx, allOps, serverSamples{})
 
 	err = conns.startStageAll(stageCleanup, time.Now(), false)
 	if err != nil {
@@ -239,23 +293,66 @@ type connections struct {
 	hosts []string
 	ws    []*websocket.Conn
 	si    serverInfo
-	info  func(data ...interface{})
-	errLn func(data ...interface{})
+	// secret is sent to clients when connecting, if set.
+	secret string
+	info   func(data ...interface{})
+	errLn  func(data ...interface{})
+	state  []clientState
+
+	// Called with the operation summaries clients report while running.
+	live func(ops bench.OpsSummaries)
+	// Closed to stop the benchmark stage on all clients.
+	stopBench     chan struct{}
+	stopBenchOnce sync.Once
+
+	// Connect with TLS if set.
+	tlsConfig *tls.Config
+	// How long to keep trying to reconnect to a client that dropped during a stage.
+	reconnect time.Duration
+}
+
+// clientState tracks connection problems with a client.
+type clientState struct {
+	// lost is when the connection was lost and not restored within the reconnect window.
+	lost time.Time
+	// benchErr is set if the client reported an error running the benchmark.
+	benchErr string
+	// failed is set when the client will no longer be used.
+	failed bool
+	// liveAck is the last live summary batch received from the client.
+	liveAck uint64
+}
+
+// clientOps are the operations downloaded from a client.
+type clientOps struct {
+	idx int
+	ops bench.Operations
 }
 
 // newConnections creates connections (but does not connect) to clients.
-func newConnections(hosts []string) *connections {
+// The secret is sent to clients when connecting.
+func newConnections(hosts []string, secret string) *connections {
 	var c connections
 	c.si = serverInfo{
 		ID:      pRandASCII(20),
-		Secret:  "",
 		Version: warpServerVersion,
 	}
+	c.secret = secret
 	c.hosts = hosts
 	c.ws = make([]*websocket.Conn, len(hosts))
+	c.state = make([]clientState, len(hosts))
+	c.stopBench = make(chan struct{})
 	return &c
 }
 
+// stopBenchmark asks all clients to stop the benchmark stage.
+// The request is sent while waiting for the stage to finish.
+func (c *connections) stopBenchmark() {
+	c.stopBenchOnce.Do(func() {
+		close(c.stopBench)
+	})
+}
+
 func (c *connections) errorF(format string, data ...interface{}) {
 	c.errLn(fmt.Sprintf(format, data...))
 }
@@ -332,9 +429,24 @@ func (c *connections) connect(i int) error {
 				host += ":" + strconv.Itoa(warpServerDefaultPort)
 			}
 			u := url.URL{Scheme: "ws", Host: host, Path: "/ws"}
+			dialer := websocket.DefaultDialer
+			if c.tlsConfig != nil {
+				u.Scheme = "wss"
+				d := *websocket.DefaultDialer
+				d.TLSClientConfig = c.tlsConfig
+				dialer = &d
+			}
 			c.info("Connecting to ", u.String())
+			var header http.Header
+			if c.secret != "" {
+				header = http.Header{warpSecretHeader: []string{c.secret}}
+			}
 			var err error
-			c.ws[i], _, err = websocket.DefaultDialer.Dial(u.String(), nil)
+			var hresp *http.Response
+			c.ws[i], hresp, err = dialer.Dial(u.String(), header)
+			if hresp != nil && hresp.StatusCode == http.StatusUnauthorized {
+				return errors.New("client rejected the secret, check --warp-client.secret")
+			}
 			if err != nil {
 				return err
 			}
@@ -404,8 +516,8 @@ func (c *connections) startStageAll(stage benchmarkStage, startAt time.Time, fai
 	var mu sync.Mutex
 	c.info("Requesting stage ", stage, " start...")
 
-	for i, conn := range c.ws {
-		if conn == nil {
+	for i := range c.ws {
+		if c.state[i].failed {
 			continue
 		}
 		wg.Add(1)
@@ -422,6 +534,7 @@ func (c *connections) startStageAll(stage benchmarkStage, startAt time.Time, fai
 					gerr = err
 				}
 				c.ws[i] = nil
+				c.state[i].failed = true
 				mu.Unlock()
 			}
 		}(i)
@@ -430,15 +543,16 @@ func (c *connections) startStageAll(stage benchmarkStage, startAt time.Time, fai
 	return gerr
 }
 
-// downloadOps will download operations from all connected clients.
+// downloadOps will download operations from all clients that ran the benchmark.
+// Clients that were lost during the benchmark are reconnected.
 // If an error is encountered the result will be ignored.
-func (c *connections) downloadOps() []bench.Operations {
+func (c *connections) downloadOps() []clientOps {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	c.info("Downloading operations...")
-	res := make([]bench.Operations, 0, len(c.ws))
-	for i, conn := range c.ws {
-		if conn == nil {
+	res := make([]clientOps, 0, len(c.ws))
+	for i := range c.ws {
+		if c.state[i].failed && c.state[i].lost.IsZero() && c.state[i].benchErr == "" {
 			continue
 		}
 		wg.Add(1)
@@ -446,7 +560,7 @@ func (c *connections) downloadOps() []bench.Operations {
 			defer wg.Done()
 			resp, err := c.roundTrip(i, serverRequest{Operation: serverReqSendOps})
 			if err != nil {
-				c.errorF("Client %v download returned error: %v\n", c.hostName(i), resp.Err)
+				c.errorF("Client %v download returned error: %v\n", c.hostName(i), err)
 				return
 			}
 			if resp.Err != "" {
@@ -454,13 +568,64 @@ func (c *connections) downloadOps() []bench.Operations {
 				return
 			}
 			c.info("Client ", c.hostName(i), ": Operations downloaded.")
+			if !c.state[i].lost.IsZero() {
+				// Reconnected, so let it clean up.
+				c.state[i].failed = false
+			}
 
 			mu.Lock()
-			res = append(res, resp.Ops)
+			res = append(res, clientOps{idx: i, ops: resp.Ops})
 			mu.Unlock()
 		}(i)
 	}
 	wg.Wait()
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].idx < res[j].idx
+	})
+	return res
+}
+
+// partialClients returns a description of every client that failed or lost
+// its connection during the benchmark, with the time window its operations cover.
+func (c *connections) partialClients(downloaded []clientOps) []string {
+	var start, end time.Time
+	got := make(map[int]bench.Operations, len(downloaded))
+	for _, d := range downloaded {
+		got[d.idx] = d.ops
+		if len(d.ops) == 0 {
+			continue
+		}
+		s, e := d.ops.TimeRange()
+		if start.IsZero() || s.Before(start) {
+			start = s
+		}
+		if e.After(end) {
+			end = e
+		}
+	}
+	const tf = "15:04:05"
+	var res []string
+	for i, st := range c.state {
+		if !st.failed && st.lost.IsZero() && st.benchErr == "" {
+			continue
+		}
+		var reason string
+		switch {
+		case st.benchErr != "":
+			reason = "benchmark failed: " + st.benchErr
+		case !st.lost.IsZero():
+			reason = "connection lost at " + st.lost.Format(tf)
+		default:
+			reason = "benchmark not started"
+		}
+		ops := got[i]
+		if len(ops) == 0 {
+			res = append(res, fmt.Sprintf("Client %v: %s. No data.", c.hosts[i], reason))
+			continue
+		}
+		s, e := ops.TimeRange()
+		res = append(res, fmt.Sprintf("Client %v: %s. Data from %s to %s of %s to %s.", c.hosts[i], reason, s.Format(tf), e.Format(tf), start.Format(tf), end.Format(tf)))
+	}
 	return res
 }
 
@@ -468,34 +633,87 @@ func (c *connections) downloadOps() []bench.Operations {
 func (c *connections) waitForStage(stage benchmarkStage, failOnErr bool, common *bench.Common) error {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
-	for i, conn := range c.ws {
-		if conn == nil {
-			// log?
+	for i := range c.ws {
+		if c.state[i].failed {
 			continue
 		}
 		wg.Add(1)
 		go func(i int) {
 			defer wg.Done()
+			var lost time.Time
+			stopSent := false
 			for {
+				if stage == stageBenchmark && !stopSent {
+					select {
+					case <-c.stopBench:
+						resp, err := c.roundTrip(i, serverRequest{Operation: serverReqStopBench})
+						switch {
+						case err != nil:
+							// Retried after the status below reconnects.
+							c.errLn(err)
+						case resp.Err != "":
+							c.errorF("Client %v returned error: %v\n", c.hostName(i), resp.Err)
+							stopSent = true
+						default:
+							stopSent = true
+						}
+					default:
+					}
+				}
 				req := serverRequest{
 					Operation: serverReqStageStatus,
 					Stage:     stage,
+					LiveAck:   c.state[i].liveAck,
 				}
 				resp, err := c.roundTrip(i, req)
 				if err != nil {
-					c.disconnect(i)
 					if failOnErr {
+						c.disconnect(i)
 						fatalIf(probe.NewError(err), "Stage failed.")
 					}
-					c.errLn(err)
+					// The client keeps running and buffers its operations,
+					// so try to reconnect for a while.
+					if lost.IsZero() {
+						lost = time.Now()
+						c.errorF("Lost connection to client %v: %v\n", c.hosts[i], err)
+					}
+					if time.Since(lost) < c.reconnect {
+						time.Sleep(time.Second)
+						continue
+					}
+					c.errorF("Giving up on client %v after %v\n", c.hosts[i], time.Since(lost).Round(time.Second))
+					c.ws[i] = nil
+					c.state[i].failed = true
+					c.state[i].lost = lost
 					return
 				}
+				if !lost.IsZero() {
+					c.info("Client ", c.hostName(i), ": Reconnected after ", time.Since(lost).Round(time.Second))
+					lost = time.Time{}
+				}
+				for _, b := range resp.Live {
+					// Batches are resent until acknowledged, so skip the ones we have.
+					if b.Seq <= c.state[i].liveAck {
+						continue
+					}
+					if c.live != nil {
+						c.live(b.Ops)
+					}
+					c.state[i].liveAck = b.Seq
+				}
 				if resp.Err != "" {
-					c.disconnect(i)
 					if failOnErr {
+						c.disconnect(i)
 						fatalIf(probe.NewError(errors.New(resp.Err)), "Stage failed. Client %v returned error.", c.hostName(i))
 					}
 					c.errorF("Client %v returned error: %v\n", c.hostName(i), resp.Err)
+					c.state[i].failed = true
+					if stage == stageBenchmark {
+						// Keep the connection to download the operations that were recorded.
+						c.state[i].benchErr = resp.Err
+						return
+					}
+					c.disconnect(i)
 					return
 				}
 				if resp.StageInfo.Finished {
@@ -563,3 +781,173 @@ func flagToJSON(ctx *cli.Context, flag cli.Flag) (string, error) {
 	}
 	return "", nil
 }
+
+const (
+	// liveWindow is the duration live throughput is calculated over.
+	liveWindow = 10 * time.Second
+	// Clients report every second, so the most recent summaries
+	// are not complete until liveDelay has passed.
+	liveDelay = 2 * time.Second
+)
+
+// liveOps keeps the operation summaries reported by clients.
+type liveOps struct {
+	mu  sync.Mutex
+	ops bench.OpsSummaries
+	// Keep all summaries instead of only the most recent.
+	keepAll bool
+}
+
+// add summaries and drop the ones no longer needed.
+func (l *liveOps) add(ops bench.OpsSummaries) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ops = append(l.ops, ops...)
+	if l.keepAll {
+		return
+	}
+	keep := l.ops.End().Add(-liveWindow - liveDelay)
+	// Readers may hold the current slice, so don't modify it.
+	kept := make(bench.OpsSummaries, 0, len(l.ops))
+	for _, op := range l.ops {
+		if op.To.After(keep) {
+			kept = append(kept, op)
+		}
+	}
+	l.ops = kept
+}
+
+// liveRate is the combined throughput of one operation type.
+type liveRate struct {
+	typ       string
+	bps, objs float64
+}
+
+// short returns the throughput in bytes, or objects if no bytes were transferred.
+func (r liveRate) short() string {
+	if r.bps > 0 {
+		return fmt.Sprintf("%.1f MiB/s", r.bps/(1<<20))
+	}
+	return fmt.Sprintf("%.2f obj/s", r.objs)
+}
+
+// speed returns the throughput without the operation type.
+func (r liveRate) speed() string {
+	if r.bps > 0 {
+		return fmt.Sprintf("%s, %.2f obj/s", r.short(), r.objs)
+	}
+	return r.short()
+}
+
+// rates returns the combined throughput of each operation type over the last liveWindow.
+// The type with the highest throughput is returned first.
+func (l *liveOps) rates() []liveRate {
+	l.mu.Lock()
+	ops := l.ops
+	l.mu.Unlock()
+	end := ops.End().Add(-liveDelay)
+	from := end.Add(-liveWindow)
+	if start := ops.Start(); from.Before(start) {
+		from = start
+	}
+	var rates []liveRate
+	for _, typ := range ops.OpTypes() {
+		if (bench.Operation{OpType: typ}).IsJobPhase() {
+			continue
+		}
+		bps, objs := ops.Rate(typ, from, end)
+		rates = append(rates, liveRate{typ: typ, bps: bps, objs: objs})
+	}
+	sort.SliceStable(rates, func(i, j int) bool {
+		if rates[i].bps != rates[j].bps {
+			return rates[i].bps > rates[j].bps
+		}
+		return rates[i].objs > rates[j].objs
+	})
+	return rates
+}
+
+// progress shows the benchmark progress on the clients with the combined live throughput.
+// The returned function stops it.
+func (l *liveOps) progress(monitor *api.Server, start time.Time, dur time.Duration) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	var pg *progressBar
+	if !globalQuiet && !globalJSON {
+		pg = newProgressBar(int64(dur), pb.U_DURATION)
+		pg.SetCaption("Benchmarking:")
+	}
+	go func() {
+		defer close(stopped)
+		tick := time.NewTicker(time.Second)
+		defer tick.Stop()
+		for {
+			select {
+			case t := <-tick.C:
+				elapsed := t.Sub(start)
+				if elapsed < 0 {
+					continue
+				}
+				if elapsed > dur {
+					elapsed = dur
+				}
+				rates := l.rates()
+				if pg != nil {
+					if len(rates) > 0 {
+						// Only the fastest fits in the caption.
+						pg.SetCaption(rates[0].short())
+					}
+					pg.Set64(int64(elapsed))
+					pg.Update()
+				}
+				status := fmt.Sprintf("Running benchmark: %0.0f%%...", 100*float64(elapsed)/float64(dur))
+				for _, r := range rates {
+					status += fmt.Sprintf(" %s: %s.", r.typ, r.speed())
+				}
+				monitor.InfoQuietln(status)
+			case <-done:
+				if pg != nil {
+					pg.Set64(int64(dur))
+					pg.Update()
+					pg.Finish()
+				}
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// autoTerm checks the reported operations every second and calls stop once
+// the throughput of the dominant operation type is stable.
+// The returned function ends checking.
+func (l *liveOps) autoTerm(threshold float64, minDur time.Duration, stop func(status string)) (cancel func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		tick := time.NewTicker(time.Second)
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+			}
+			l.mu.Lock()
+			ops := l.ops
+			l.mu.Unlock()
+			if stable, status := ops.AutoTerm(ops.Dominant(), ops.End().Add(-liveDelay), threshold, minDur); stable {
+				stop(status)
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
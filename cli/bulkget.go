@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"github.com/joshcarter/warp-ds3/pkg/bench"
+	"github.com/minio/cli"
+	"github.com/minio/pkg/console"
+)
+
+var bulkGetFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "objects",
+		Value: 2500,
+		Usage: "Number of objects to upload.",
+	},
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "1MiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+	cli.IntFlag{
+		Name:  "bulk.num",
+		Value: 100,
+		Usage: "Number of objects per bulk get operation.",
+	},
+}
+
+// BulkGet command.
+var bulkGetCmd = cli.Command{
+	Name:   "bulkget",
+	Usage:  "benchmark bulk get objects",
+	Action: mainBulkGet,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, bulkGetFlags, bulkJobFlags, genFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainBulkGet is the entry point for bulkget command.
+func mainBulkGet(ctx *cli.Context) error {
+	checkBulkGetSyntax(ctx)
+	src := newGenSource(ctx, "obj.size")
+	client, endpoint := newClient(ctx)
+	b := bench.BulkGet{
+		Common: bench.Common{
+			Client:         client,
+			Concurrency:    ctx.Int("concurrent"),
+			Source:         src,
+			Bucket:         ctx.String("bucket"),
+			DataPolicy:     ctx.String("data-policy"),
+			ClientEndpoint: endpoint,
+			BulkOpts:       bulkOpts(ctx),
+		},
+		BulkNum:       ctx.Int("bulk.num"),
+		CreateObjects: ctx.Int("objects"),
+	}
+	return runBench(ctx, &b)
+}
+
+func checkBulkGetSyntax(ctx *cli.Context) {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	if ctx.Int("objects") <= 0 {
+		console.Fatal("There must be more than 0 objects.")
+	}
+	if ctx.Int("bulk.num") <= 0 {
+		console.Fatal("Bulk operation must have more than 0 objects.")
+	}
+	checkBulkJobSyntax(ctx)
+
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
@@ -1,9 +1,12 @@
 package cli
 
 import (
+	"time"
+
+	"github.com/SpectraLogic/ds3_go_sdk/ds3/models"
 	"github.com/joshcarter/warp-ds3/pkg/bench"
 	"github.com/minio/cli"
-	"github.com/minio/minio-go/v7"
+	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/pkg/console"
 )
 
@@ -18,6 +21,87 @@ var bulkPutFlags = []cli.Flag{
 		Value: 100,
 		Usage: "Number of objects per bulk put operation.",
 	},
+	cli.StringFlag{
+		Name:  "bulk.blob-size",
+		Value: "",
+		Usage: "Maximum blob size objects are split into. Can be a number or 10KiB/MiB/GiB. Server default if not set.",
+	},
+	cli.BoolFlag{
+		Name:  "bulk.minimize-spanning",
+		Usage: "Minimize spanning of jobs across media. Server default if not set.",
+	},
+	cli.BoolFlag{
+		Name:  "bulk.verify-after-write",
+		Usage: "Verify data after it has been written to media. Data policy default if not set.",
+	},
+	cli.BoolFlag{
+		Name:  "compare-naked",
+		Usage: "Interleave bulk jobs with naked puts of the same objects count and size, recorded as NAKEDPUT.",
+	},
+}
+
+// bulkPersistFlags are shared by benchmarks running DS3 bulk put jobs.
+var bulkPersistFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "bulk.wait-persist",
+		Usage: "After the transfer, wait for put jobs to complete and report persist latency. Jobs are polled in the background.",
+	},
+	cli.DurationFlag{
+		Name:  "bulk.persist-timeout",
+		Value: 10 * time.Minute,
+		Usage: "Maximum time to wait for a put job to complete with bulk.wait-persist. Jobs not completed in time are recorded without persist latency.",
+	},
+}
+
+// bulkJobFlags are shared by all benchmarks running DS3 bulk jobs.
+var bulkJobFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "bulk.priority",
+		Value: "",
+		Usage: "Priority of bulk jobs. Can be CRITICAL/URGENT/HIGH/NORMAL/LOW/BACKGROUND. Data policy default if not set.",
+	},
+	cli.BoolFlag{
+		Name:  "bulk.aggregate",
+		Usage: "Allow the server to aggregate bulk jobs. Server default if not set.",
+	},
+	cli.IntFlag{
+		Name:  "bulk.streams",
+		Value: 1,
+		Usage: "Number of blobs transferred concurrently within each bulk job.",
+	},
+	cli.IntFlag{
+		Name:  "bulk.retries",
+		Value: 3,
+		Usage: "Number of retries for a failed chunk poll or blob transfer before the job fails.",
+	},
+	cli.DurationFlag{
+		Name:  "bulk.retry-backoff",
+		Value: time.Second,
+		Usage: "Wait before the first retry. Doubled for every following retry.",
+	},
+	cli.BoolFlag{
+		Name:  "bulk.abandon-failed",
+		Usage: "Leave failed bulk jobs on the server instead of cancelling them.",
+	},
+	cli.DurationFlag{
+		Name:  "bulk.chunk-wait-min",
+		Value: 100 * time.Millisecond,
+		Usage: "Minimum wait before polling for ready chunks again, regardless of the server's Retry-After.",
+	},
+	cli.DurationFlag{
+		Name:  "bulk.chunk-wait-max",
+		Value: 5 * time.Second,
+		Usage: "Maximum wait before polling for ready chunks again. Used when the server sends no Retry-After.",
+	},
+	cli.DurationFlag{
+		Name:  "bulk.job-poll",
+		Value: time.Second,
+		Usage: "Interval between job status polls when waiting for the server to complete a job.",
+	},
+	cli.BoolFlag{
+		Name:  "bulk.phase-ops",
+		Usage: "Also record job creation, chunk waits and blob transfers of each bulk job as separate operations.",
+	},
 }
 
 // Put command.
@@ -26,7 +110,7 @@ var bulkPutCmd = cli.Command{
 	Usage:  "benchmark bulk put objects",
 	Action: mainBulkPut,
 	Before: setGlobalsFromContext,
-	Flags:  combineFlags(globalFlags, ioFlags, bulkPutFlags, genFlags, benchFlags, analyzeFlags),
+	Flags:  combineFlags(globalFlags, ioFlags, bulkPutFlags, bulkPersistFlags, bulkJobFlags, genFlags, benchFlags, analyzeFlags),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -42,30 +126,72 @@ FLAGS:
 func mainBulkPut(ctx *cli.Context) error {
 	checkBulkPutSyntax(ctx)
 	src := newGenSource(ctx, "obj.size")
+	client, endpoint := newClient(ctx)
 	b := bench.BulkPut{
 		Common: bench.Common{
-			Client:      newClient(ctx),
-			Concurrency: ctx.Int("concurrent"),
-			Source:      src,
-			Bucket:      ctx.String("bucket"),
-			Endpoint:    parseHosts(ctx.String("host"), ctx.Bool("resolve-host"))[0], // FIXME: ugly
-			PutOpts:     bulkPutOpts(ctx),
+			Client:         client,
+			Concurrency:    ctx.Int("concurrent"),
+			Source:         src,
+			Bucket:         ctx.String("bucket"),
+			DataPolicy:     ctx.String("data-policy"),
+			ClientEndpoint: endpoint,
+			BulkOpts:       bulkOpts(ctx),
 		},
-		BulkNum: ctx.Int("bulk.num"),
+		BulkNum:      ctx.Int("bulk.num"),
+		CompareNaked: ctx.Bool("compare-naked"),
 	}
 	return runBench(ctx, &b)
 }
 
-// putOpts retrieves put options from the context.
-func bulkPutOpts(ctx *cli.Context) minio.PutObjectOptions {
-	pSize, _ := toSize(ctx.String("part.size"))
-	return minio.PutObjectOptions{
-		ServerSideEncryption: newSSE(ctx),
-		DisableMultipart:     ctx.Bool("disable-multipart"),
-		SendContentMd5:       ctx.Bool("md5"),
-		StorageClass:         ctx.String("storage-class"),
-		PartSize:             pSize,
+// bulkOpts retrieves bulk job options from the context.
+func bulkOpts(ctx *cli.Context) bench.BulkOptions {
+	var priority models.Priority
+	if err := priority.UnmarshalText([]byte(ctx.String("bulk.priority"))); err != nil {
+		fatalIf(probe.NewError(err), "Invalid bulk.priority value")
+	}
+	var blobSize uint64
+	if ctx.IsSet("bulk.blob-size") {
+		var err error
+		blobSize, err = toSize(ctx.String("bulk.blob-size"))
+		fatalIf(probe.NewError(err), "Invalid bulk.blob-size value")
+	}
+	return bench.BulkOptions{
+		Retries:          ctx.Int("bulk.retries"),
+		RetryBackoff:     ctx.Duration("bulk.retry-backoff"),
+		AbandonFailed:    ctx.Bool("bulk.abandon-failed"),
+		MinChunkWait:     ctx.Duration("bulk.chunk-wait-min"),
+		MaxChunkWait:     ctx.Duration("bulk.chunk-wait-max"),
+		PhaseOps:         ctx.Bool("bulk.phase-ops"),
+		JobPollInterval:  ctx.Duration("bulk.job-poll"),
+		WaitPersist:      ctx.Bool("bulk.wait-persist"),
+		PersistTimeout:   ctx.Duration("bulk.persist-timeout"),
+		Streams:          ctx.Int("bulk.streams"),
+		MaxUploadSize:    int64(blobSize),
+		Priority:         priority,
+		Aggregating:      optionalBool(ctx, "bulk.aggregate"),
+		MinimizeSpanning: optionalBool(ctx, "bulk.minimize-spanning"),
+		VerifyAfterWrite: optionalBool(ctx, "bulk.verify-after-write"),
+	}
+}
+
+// optionalBool returns the value of a bool flag, or nil if it is not set.
+func optionalBool(ctx *cli.Context, name string) *bool {
+	if !ctx.IsSet(name) {
+		return nil
+	}
+	b := ctx.Bool(name)
+	return &b
+}
+
+// bulkJobComment returns the bulk job settings in effect,
+// or an empty string if the command does not run bulk jobs.
+func bulkJobComment(ctx *cli.Context) string {
+	for _, flag := range ctx.Command.Flags {
+		if flag.GetName() == "bulk.priority" {
+			return bulkOpts(ctx).String()
+		}
 	}
+	return ""
 }
 
 func checkBulkPutSyntax(ctx *cli.Context) {
@@ -75,7 +201,36 @@ func checkBulkPutSyntax(ctx *cli.Context) {
 	if ctx.Int("bulk.num") <= 0 {
 		console.Fatal("Bulk operation must have more than 0 objects.")
 	}
+	checkBulkJobSyntax(ctx)
+	checkBulkPersistSyntax(ctx)
 
 	checkAnalyze(ctx)
 	checkBenchmark(ctx)
 }
+
+func checkBulkPersistSyntax(ctx *cli.Context) {
+	if ctx.Bool("bulk.wait-persist") && ctx.Duration("bulk.persist-timeout") <= 0 {
+		console.Fatal("bulk.persist-timeout must be positive.")
+	}
+}
+
+func checkBulkJobSyntax(ctx *cli.Context) {
+	if ctx.Int("bulk.streams") <= 0 {
+		console.Fatal("bulk.streams must be at least 1.")
+	}
+	if ctx.Int("bulk.retries") < 0 {
+		console.Fatal("bulk.retries cannot be negative.")
+	}
+	if ctx.Duration("bulk.retry-backoff") < 0 {
+		console.Fatal("bulk.retry-backoff cannot be negative.")
+	}
+	if ctx.Duration("bulk.job-poll") <= 0 {
+		console.Fatal("bulk.job-poll must be positive.")
+	}
+	if ctx.Duration("bulk.chunk-wait-min") < 0 {
+		console.Fatal("bulk.chunk-wait-min cannot be negative.")
+	}
+	if ctx.Duration("bulk.chunk-wait-max") < ctx.Duration("bulk.chunk-wait-min") {
+		console.Fatal("bulk.chunk-wait-max cannot be less than bulk.chunk-wait-min.")
+	}
+}
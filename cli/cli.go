@@ -86,6 +86,12 @@ func Main(args []string) {
 func init() {
 	a := []cli.Command{
 		bulkPutCmd,
+		bulkGetCmd,
+		bulkVerifyCmd,
+		statCmd,
+		listCmd,
+		deleteCmd,
+		mixedCmd,
 		putCmd,
 	}
 	b := []cli.Command{
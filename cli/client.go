@@ -21,13 +21,16 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
+	"fmt"
 	"github.com/SpectraLogic/ds3_go_sdk/ds3"
 	"github.com/SpectraLogic/ds3_go_sdk/ds3/networking"
 	"log"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/joshcarter/warp-ds3/pkg"
@@ -46,33 +49,117 @@ const (
 	hostSelectTypeWeighed    hostSelectType = "weighed"
 )
 
-func newClient(ctx *cli.Context) func() (cl *ds3.Client, done func()) {
+// newClient returns a function handing out clients for the hosts in the context
+// and a function returning the endpoint of a client it handed out.
+func newClient(ctx *cli.Context) (func() (cl *ds3.Client, done func()), func(cl *ds3.Client) string) {
 	hosts := parseHosts(ctx.String("host"), ctx.Bool("resolve-host"))
 	if len(hosts) == 0 {
 		fatalIf(probe.NewError(errors.New("no host defined")), "Unable to create DS3 client")
 	}
-	if len(hosts) > 1 {
-		fatalIf(probe.NewError(errors.New("DS3 only supports one host")), "DS3 only supports one host")
+	clients := make([]*ds3.Client, len(hosts))
+	endpoints := make(map[*ds3.Client]string, len(hosts))
+	for i, host := range hosts {
+		cl, err := getClient(ctx, host)
+		fatalIf(probe.NewError(err), "Unable to create DS3 client")
+		clients[i] = cl
+		endpoints[cl] = host
 	}
+	endpoint := func(cl *ds3.Client) string {
+		return endpoints[cl]
+	}
+
+	if len(clients) == 1 {
+		cl := clients[0]
+		return func() (*ds3.Client, func()) {
+			return cl, func() {}
+		}, endpoint
+	}
+
+	switch hostSelectType(ctx.String("host-select")) {
+	case hostSelectTypeRoundrobin:
+		return roundRobinClients(clients), endpoint
+	case hostSelectTypeWeighed:
+		return weighedClients(clients), endpoint
+	}
+	fatalIf(probe.NewError(fmt.Errorf("unknown host-select value %q", ctx.String("host-select"))), "Invalid host-select")
+	return nil, nil
+}
 
-	cl, err := getClient(ctx, hosts[0])
-	fatalIf(probe.NewError(err), "Unable to create DS3 client")
+// roundRobinClients hands out the clients in turn.
+func roundRobinClients(clients []*ds3.Client) func() (*ds3.Client, func()) {
+	var current int
+	var mu sync.Mutex
+	return func() (*ds3.Client, func()) {
+		mu.Lock()
+		now := current % len(clients)
+		current++
+		mu.Unlock()
+		return clients[now], func() {}
+	}
+}
 
+// weighedClients hands out the client with the fewest requests running.
+// Ties go to the client that finished a request the longest time ago.
+func weighedClients(clients []*ds3.Client) func() (*ds3.Client, func()) {
+	running := make([]int, len(clients))
+	lastFinished := make([]time.Time, len(clients))
+	{
+		// Start with a random host
+		now := time.Now()
+		off := rand.Intn(len(clients))
+		for i := range clients {
+			lastFinished[i] = now.Add(time.Duration((i + off) % len(clients)))
+		}
+	}
+	var mu sync.Mutex
 	return func() (*ds3.Client, func()) {
-		return cl, func() {}
+		mu.Lock()
+		defer mu.Unlock()
+		minIdx := 0
+		for i := range clients {
+			if running[i] < running[minIdx] || (running[i] == running[minIdx] && lastFinished[i].Before(lastFinished[minIdx])) {
+				minIdx = i
+			}
+		}
+		// Notify that we have another running.
+		running[minIdx]++
+		return clients[minIdx], func() {
+			mu.Lock()
+			defer mu.Unlock()
+			running[minIdx]--
+			if running[minIdx] < 0 {
+				panic("negative running count")
+			}
+			lastFinished[minIdx] = time.Now()
+		}
 	}
 }
 
 // getClient creates a client with the specified host and the options set in the context.
+// The DS3 SDK creates its own transport, so only TLS, certificate verification
+// and the proxy from the environment apply. Custom CAs must be trusted by the
+// system, for example by setting SSL_CERT_FILE.
 func getClient(ctx *cli.Context, host string) (*ds3.Client, error) {
-	cl := ds3.NewClientBuilder(
-		&url.URL{Scheme: "http", Host: host},
+	scheme := "http"
+	if ctx.Bool("tls") {
+		scheme = "https"
+	}
+	endpoint := &url.URL{Scheme: scheme, Host: host}
+	builder := ds3.NewClientBuilder(
+		endpoint,
 		&networking.Credentials{
 			AccessId: ctx.String("access-key"),
 			Key:      ctx.String("secret-key")}).
-		BuildClient()
+		WithIgnoreServerCertificate(ctx.Bool("insecure"))
 
-	return cl, nil
+	proxy, err := http.ProxyFromEnvironment(&http.Request{URL: endpoint})
+	if err != nil {
+		return nil, err
+	}
+	if proxy != nil {
+		builder = builder.WithProxy(proxy)
+	}
+	return builder.BuildClient(), nil
 }
 
 func clientTransport(ctx *cli.Context) http.RoundTripper {
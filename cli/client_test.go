@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/SpectraLogic/ds3_go_sdk/ds3"
+)
+
+func TestWeighedClients(t *testing.T) {
+	clients := []*ds3.Client{{}, {}, {}}
+	get := weighedClients(clients)
+	idx := func(cl *ds3.Client) int {
+		for i, c := range clients {
+			if c == cl {
+				return i
+			}
+		}
+		t.Fatal("unknown client returned")
+		return -1
+	}
+
+	// Concurrent requests are spread evenly.
+	var dones []func()
+	counts := make([]int, len(clients))
+	for i := 0; i < 30; i++ {
+		cl, done := get()
+		counts[idx(cl)]++
+		dones = append(dones, done)
+	}
+	for i, n := range counts {
+		if n != 10 {
+			t.Errorf("client %d: want 10 running, got %d", i, n)
+		}
+	}
+	for _, done := range dones {
+		done()
+	}
+
+	// Sequential requests don't stick to one client.
+	seen := make(map[int]struct{})
+	for i := 0; i < 30; i++ {
+		cl, done := get()
+		seen[idx(cl)] = struct{}{}
+		done()
+	}
+	if len(seen) < 2 {
+		t.Errorf("want requests spread over more than one client, got %d", len(seen))
+	}
+}
+
+func TestRoundRobinClients(t *testing.T) {
+	clients := []*ds3.Client{{}, {}}
+	get := roundRobinClients(clients)
+	for i := 0; i < 4; i++ {
+		cl, done := get()
+		if cl != clients[i%2] {
+			t.Errorf("request %d: unexpected client", i)
+		}
+		done()
+	}
+}
@@ -27,7 +27,21 @@ import (
 	"github.com/minio/pkg/console"
 )
 
-var clientFlags = []cli.Flag{}
+var clientFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:   "secret",
+		Usage:  "Only accept servers that present this shared secret with --warp-client.secret",
+		EnvVar: appNameUC + "_CLIENT_SECRET",
+	},
+	cli.StringFlag{
+		Name:  "tls.cert",
+		Usage: "Serve TLS with the certificate in this PEM file. Requires --tls.key",
+	},
+	cli.StringFlag{
+		Name:  "tls.key",
+		Usage: "Private key in PEM format for --tls.cert",
+	},
+}
 
 // Put command.
 var clientCmd = cli.Command{
@@ -50,6 +64,9 @@ FLAGS:
 EXAMPLES:
   1. Listen on port '6001' with ip 192.168.1.101:
      {{.Prompt}} {{.HelpName}} 192.168.1.101:6001
+
+  2. Require a shared secret and serve TLS:
+     {{.Prompt}} {{.HelpName}} --secret=$WARP_CLIENT_SECRET --tls.cert=public.crt --tls.key=private.key
  `,
 }
 
@@ -69,11 +86,24 @@ func mainClient(ctx *cli.Context) error {
 	default:
 		fatal(errInvalidArgument(), "Too many parameters")
 	}
-	http.HandleFunc("/ws", serveWs)
+	secret := ctx.String("secret")
+	if secret == "" {
+		console.Infoln("No secret set. Any server that can reach this client can run benchmarks.")
+	}
+	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		serveWs(w, r, secret)
+	})
 	console.Infoln("Listening on", addr)
+	if cert := ctx.String("tls.cert"); cert != "" {
+		fatalIf(probe.NewError(http.ListenAndServeTLS(addr, cert, ctx.String("tls.key"), nil)), "Unable to start client")
+		return nil
+	}
 	fatalIf(probe.NewError(http.ListenAndServe(addr, nil)), "Unable to start client")
 	return nil
 }
 
 func checkClientSyntax(ctx *cli.Context) {
+	if (ctx.String("tls.cert") == "") != (ctx.String("tls.key") == "") {
+		fatal(errInvalidArgument(), "--tls.cert and --tls.key must be used together")
+	}
 }
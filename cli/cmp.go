@@ -90,6 +90,8 @@ func printCompare(ctx *cli.Context, before, after bench.Operations) {
 		}
 	}
 	_ = wrSegs
+	// Job phases overlap the operations covering whole jobs.
+	before, after = before.FilterByJobPhase(false), after.FilterByJobPhase(false)
 	isMultiOp := before.IsMixed()
 	if isMultiOp != after.IsMixed() {
 		console.Fatal("Cannot compare multi-operation to single operation.")
@@ -136,7 +138,7 @@ func printCompare(ctx *cli.Context, before, after bench.Operations) {
 			console.Println("Duration:", timeDur(before), "->", timeDur(after))
 		}
 		if cmp.Reqs.Before.AvgObjSize != cmp.Reqs.After.AvgObjSize {
-			console.Println("Object size: %d->%d, ", cmp.Reqs.Before.AvgObjSize, cmp.Reqs.After.AvgObjSize)
+			console.Printf("Object size: %d->%d\n", cmp.Reqs.Before.AvgObjSize, cmp.Reqs.After.AvgObjSize)
 		}
 		console.Println("* Average:", cmp.Average)
 		console.Println("* Requests:", cmp.Reqs.String())
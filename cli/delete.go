@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"github.com/joshcarter/warp-ds3/pkg/bench"
+	"github.com/minio/cli"
+	"github.com/minio/pkg/console"
+)
+
+var deleteFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "objects",
+		Value: 25000,
+		Usage: "Number of objects to upload. The benchmark stops when all are deleted.",
+	},
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "1KiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+	cli.IntFlag{
+		Name:  "batch",
+		Value: 100,
+		Usage: "Number of objects to delete per request. 1 uses DeleteObject, larger batches use multi-object delete.",
+	},
+}
+
+// Delete command.
+var deleteCmd = cli.Command{
+	Name:   "delete",
+	Usage:  "benchmark delete objects",
+	Action: mainDelete,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, deleteFlags, genFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainDelete is the entry point for delete command.
+func mainDelete(ctx *cli.Context) error {
+	checkDeleteSyntax(ctx)
+	src := newGenSource(ctx, "obj.size")
+	client, endpoint := newClient(ctx)
+	b := bench.Delete{
+		Common: bench.Common{
+			Client:         client,
+			Concurrency:    ctx.Int("concurrent"),
+			Source:         src,
+			Bucket:         ctx.String("bucket"),
+			DataPolicy:     ctx.String("data-policy"),
+			ClientEndpoint: endpoint,
+		},
+		CreateObjects: ctx.Int("objects"),
+		BatchSize:     ctx.Int("batch"),
+	}
+	return runBench(ctx, &b)
+}
+
+func checkDeleteSyntax(ctx *cli.Context) {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	if ctx.Int("objects") <= 0 {
+		console.Fatal("There must be more than 0 objects.")
+	}
+	if ctx.Int("batch") <= 0 {
+		console.Fatal("batch size must be 1 or bigger")
+	}
+	if ctx.Int("batch") > 1000 {
+		console.Fatal("batch size must be 1000 or less")
+	}
+
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
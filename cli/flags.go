@@ -133,6 +133,9 @@ func commandLine(ctx *cli.Context) string {
 		}
 		s += " --" + flag.GetName() + "=" + val
 	}
+	if bulk := bulkJobComment(ctx); bulk != "" {
+		s += "\n" + bulk
+	}
 	return s
 }
 
@@ -158,7 +161,7 @@ var ioFlags = []cli.Flag{
 	},
 	cli.BoolFlag{
 		Name:   "tls",
-		Usage:  "Use TLS (HTTPS) for transport",
+		Usage:  "Use TLS (HTTPS) for transport. Server certificates are verified against the system CAs, set SSL_CERT_FILE to use a custom CA bundle",
 		EnvVar: appNameUC + "_TLS",
 	},
 	cli.StringFlag{
@@ -181,6 +184,11 @@ var ioFlags = []cli.Flag{
 		Value: appName + "-benchmark-bucket",
 		Usage: "Bucket to use for benchmark data. ALL DATA WILL BE DELETED IN BUCKET!",
 	},
+	cli.StringFlag{
+		Name:  "data-policy",
+		Value: "",
+		Usage: "Name or ID of the DS3 data policy to create the bucket with. An existing bucket must use this policy.",
+	},
 	cli.StringFlag{
 		Name:  "host-select",
 		Value: string(hostSelectTypeWeighed),
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"github.com/joshcarter/warp-ds3/pkg/bench"
+	"github.com/minio/cli"
+	"github.com/minio/pkg/console"
+)
+
+var listFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "objects",
+		Value: 10000,
+		Usage: "Number of objects to upload.",
+	},
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "1KiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+	cli.IntFlag{
+		Name:  "list.max-keys",
+		Value: 1000,
+		Usage: "Number of keys requested per listing page.",
+	},
+	cli.StringFlag{
+		Name:  "list.mode",
+		Value: "bucket",
+		Usage: "Listing to benchmark. Can be 'bucket' for GetBucket or 'details' for GetObjectsWithFullDetailsSpectraS3.",
+	},
+}
+
+// List command.
+var listCmd = cli.Command{
+	Name:   "list",
+	Usage:  "benchmark list objects",
+	Action: mainList,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, listFlags, genFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainList is the entry point for list command.
+func mainList(ctx *cli.Context) error {
+	checkListSyntax(ctx)
+	src := newGenSource(ctx, "obj.size")
+	client, endpoint := newClient(ctx)
+	b := bench.List{
+		Common: bench.Common{
+			Client:         client,
+			Concurrency:    ctx.Int("concurrent"),
+			Source:         src,
+			Bucket:         ctx.String("bucket"),
+			DataPolicy:     ctx.String("data-policy"),
+			ClientEndpoint: endpoint,
+		},
+		CreateObjects: ctx.Int("objects"),
+		MaxKeys:       ctx.Int("list.max-keys"),
+		FullDetails:   ctx.String("list.mode") == "details",
+	}
+	return runBench(ctx, &b)
+}
+
+func checkListSyntax(ctx *cli.Context) {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	if ctx.Int("objects") <= 0 {
+		console.Fatal("There must be more than 0 objects.")
+	}
+	if ctx.Int("list.max-keys") <= 0 {
+		console.Fatal("list.max-keys must be more than 0.")
+	}
+	switch ctx.String("list.mode") {
+	case "bucket", "details":
+	default:
+		console.Fatal("list.mode must be 'bucket' or 'details'.")
+	}
+
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"net/http"
+
+	"github.com/joshcarter/warp-ds3/pkg/bench"
+	"github.com/minio/cli"
+	"github.com/minio/pkg/console"
+)
+
+var mixedFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "objects",
+		Value: 2500,
+		Usage: "Number of objects to upload before the benchmark starts.",
+	},
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "1MiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+	cli.IntFlag{
+		Name:  "bulk.num",
+		Value: 100,
+		Usage: "Number of objects per bulk put and bulk get operation.",
+	},
+	cli.Float64Flag{
+		Name:  "bulkput-distrib",
+		Usage: "The amount of BULKPUT operations.",
+		Value: 15,
+	},
+	cli.Float64Flag{
+		Name:  "bulkget-distrib",
+		Usage: "The amount of BULKGET operations.",
+		Value: 45,
+	},
+	cli.Float64Flag{
+		Name:  "stat-distrib",
+		Usage: "The amount of HEAD operations.",
+		Value: 30,
+	},
+	cli.Float64Flag{
+		Name:  "delete-distrib",
+		Usage: "The amount of DELETE operations.",
+		Value: 10,
+	},
+}
+
+// Mixed command.
+var mixedCmd = cli.Command{
+	Name:   "mixed",
+	Usage:  "benchmark mixed bulk put/bulk get/stat/delete objects",
+	Action: mainMixed,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, mixedFlags, bulkPersistFlags, bulkJobFlags, genFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainMixed is the entry point for mixed command.
+func mainMixed(ctx *cli.Context) error {
+	checkMixedSyntax(ctx)
+	src := newGenSource(ctx, "obj.size")
+	client, endpoint := newClient(ctx)
+	b := bench.Mixed{
+		Common: bench.Common{
+			Client:         client,
+			Concurrency:    ctx.Int("concurrent"),
+			Source:         src,
+			Bucket:         ctx.String("bucket"),
+			DataPolicy:     ctx.String("data-policy"),
+			ClientEndpoint: endpoint,
+			BulkOpts:       bulkOpts(ctx),
+		},
+		CreateObjects: ctx.Int("objects"),
+		BulkNum:       ctx.Int("bulk.num"),
+		Dist: &bench.MixedDistribution{
+			Distribution: map[string]float64{
+				"BULKPUT":         ctx.Float64("bulkput-distrib"),
+				"BULKGET":         ctx.Float64("bulkget-distrib"),
+				http.MethodHead:   ctx.Float64("stat-distrib"),
+				http.MethodDelete: ctx.Float64("delete-distrib"),
+			},
+		},
+	}
+	return runBench(ctx, &b)
+}
+
+func checkMixedSyntax(ctx *cli.Context) {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	if ctx.Int("objects") <= 0 {
+		console.Fatal("There must be more than 0 objects.")
+	}
+	if ctx.Int("bulk.num") <= 0 {
+		console.Fatal("Bulk operation must have more than 0 objects.")
+	}
+	total := 0.0
+	for _, flag := range []string{"bulkput-distrib", "bulkget-distrib", "stat-distrib", "delete-distrib"} {
+		if ctx.Float64(flag) < 0 {
+			console.Fatal(flag, " must not be negative.")
+		}
+		total += ctx.Float64(flag)
+	}
+	if total <= 0 {
+		console.Fatal("At least one operation must have a distribution above 0.")
+	}
+	checkBulkJobSyntax(ctx)
+	checkBulkPersistSyntax(ctx)
+
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
@@ -61,14 +61,16 @@ FLAGS:
 func mainPut(ctx *cli.Context) error {
 	checkPutSyntax(ctx)
 	src := newGenSource(ctx, "obj.size")
+	client, endpoint := newClient(ctx)
 	b := bench.Put{
 		Common: bench.Common{
-			Client:      newClient(ctx),
-			Concurrency: ctx.Int("concurrent"),
-			Source:      src,
-			Bucket:      ctx.String("bucket"),
-			Endpoint:    "",
-			PutOpts:     putOpts(ctx),
+			Client:         client,
+			Concurrency:    ctx.Int("concurrent"),
+			Source:         src,
+			Bucket:         ctx.String("bucket"),
+			DataPolicy:     ctx.String("data-policy"),
+			ClientEndpoint: endpoint,
+			PutOpts:        putOpts(ctx),
 		},
 	}
 	return runBench(ctx, &b)
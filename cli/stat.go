@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"github.com/joshcarter/warp-ds3/pkg/bench"
+	"github.com/minio/cli"
+	"github.com/minio/pkg/console"
+)
+
+var statFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "objects",
+		Value: 10000,
+		Usage: "Number of objects to upload.",
+	},
+	cli.StringFlag{
+		Name:  "obj.size",
+		Value: "1KiB",
+		Usage: "Size of each generated object. Can be a number or 10KiB/MiB/GiB. All sizes are base 2 binary.",
+	},
+	cli.StringFlag{
+		Name:  "stat.mode",
+		Value: "head",
+		Usage: "Metadata requests to issue. Can be 'head' for HeadObject, 'details' for GetObjectDetailsSpectraS3 or 'both'.",
+	},
+}
+
+// Stat command.
+var statCmd = cli.Command{
+	Name:   "stat",
+	Usage:  "benchmark stat objects (get file info)",
+	Action: mainStat,
+	Before: setGlobalsFromContext,
+	Flags:  combineFlags(globalFlags, ioFlags, statFlags, genFlags, benchFlags, analyzeFlags),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}`,
+}
+
+// mainStat is the entry point for stat command.
+func mainStat(ctx *cli.Context) error {
+	checkStatSyntax(ctx)
+	src := newGenSource(ctx, "obj.size")
+	mode := ctx.String("stat.mode")
+	client, endpoint := newClient(ctx)
+	b := bench.Stat{
+		Common: bench.Common{
+			Client:         client,
+			Concurrency:    ctx.Int("concurrent"),
+			Source:         src,
+			Bucket:         ctx.String("bucket"),
+			DataPolicy:     ctx.String("data-policy"),
+			ClientEndpoint: endpoint,
+		},
+		CreateObjects: ctx.Int("objects"),
+		Head:          mode == "head" || mode == "both",
+		Details:       mode == "details" || mode == "both",
+	}
+	return runBench(ctx, &b)
+}
+
+func checkStatSyntax(ctx *cli.Context) {
+	if ctx.NArg() > 0 {
+		console.Fatal("Command takes no arguments")
+	}
+	if ctx.Int("objects") <= 0 {
+		console.Fatal("There must be more than 0 objects.")
+	}
+	switch ctx.String("stat.mode") {
+	case "head", "details", "both":
+	default:
+		console.Fatal("stat.mode must be 'head', 'details' or 'both'.")
+	}
+
+	checkAnalyze(ctx)
+	checkBenchmark(ctx)
+}
@@ -33,6 +33,17 @@ type Aggregated struct {
 	// MixedServerStats and MixedThroughputByHost is populated only when data is mixed.
 	MixedServerStats      *Throughput           `json:"mixed_server_stats,omitempty"`
 	MixedThroughputByHost map[string]Throughput `json:"mixed_throughput_by_host,omitempty"`
+	// JobPhases is populated when bulk job phases were recorded.
+	JobPhases []JobPhase `json:"job_phases,omitempty"`
+}
+
+// JobPhase contains the time bulk jobs spent in a single phase.
+type JobPhase struct {
+	Type         string  `json:"type"`
+	N            int     `json:"n"`
+	Errors       int     `json:"errors"`
+	PerJobMillis int     `json:"per_job_millis"`
+	Share        float64 `json:"share"`
 }
 
 // Operation returns statistics for a single operation type.
@@ -82,8 +93,6 @@ type Options struct {
 
 // Aggregate returns statistics when only a single operation was running concurrently.
 func Aggregate(o bench.Operations, opts Options) Aggregated {
-	o.SortByStartTime()
-	types := o.OpTypes()
 	a := Aggregated{
 		Type:                  "single",
 		Mixed:                 false,
@@ -91,6 +100,21 @@ func Aggregate(o bench.Operations, opts Options) Aggregated {
 		MixedServerStats:      nil,
 		MixedThroughputByHost: nil,
 	}
+	for _, p := range o.JobPhases() {
+		a.JobPhases = append(a.JobPhases, JobPhase{
+			Type:         p.OpType,
+			N:            p.N,
+			Errors:       p.Errors,
+			PerJobMillis: durToMillis(p.PerJob),
+			Share:        p.Share,
+		})
+	}
+	// Phases overlap the operations covering whole jobs.
+	if len(a.JobPhases) > 0 {
+		o = o.FilterByJobPhase(false)
+	}
+	o.SortByStartTime()
+	types := o.OpTypes()
 	isMixed := o.IsMixed()
 	opts.Prefiltered = opts.Prefiltered || o.HasError()
 
@@ -62,6 +62,14 @@ type SingleSizedRequests struct {
 	// Time to first byte if applicable.
 	FirstByte *TTFB `json:"first_byte,omitempty"`
 
+	// Average time spent waiting for cache, if applicable.
+	CacheWaitAvgMillis int `json:"cache_wait_avg_millis,omitempty"`
+
+	// Average time until the transfer ended and until the job was completed,
+	// for operations that waited for job completion.
+	IngestAvgMillis  int `json:"ingest_avg_millis,omitempty"`
+	PersistAvgMillis int `json:"persist_avg_millis,omitempty"`
+
 	// FirstAccess is filled if the same object is accessed multiple times.
 	// This records the first touch of the object.
 	FirstAccess *SingleSizedRequests `json:"first_access,omitempty"`
@@ -87,6 +95,9 @@ func (a *SingleSizedRequests) fill(ops bench.Operations) {
 	a.SlowestMillis = durToMillis(ops.Median(1).Duration())
 	a.FastestMillis = durToMillis(ops.Median(0).Duration())
 	a.FirstByte = TtfbFromBench(ops.TTFB(start, end))
+	a.CacheWaitAvgMillis = durToMillis(ops.AvgCacheWait())
+	ingest, persist, _ := ops.PersistLatency()
+	a.IngestAvgMillis, a.PersistAvgMillis = durToMillis(ingest), durToMillis(persist)
 	for i := range a.DurPct[:] {
 		a.DurPct[i] = durToMillis(ops.Median(float64(i) / 100).Duration())
 	}
@@ -135,6 +146,14 @@ type RequestSizeRange struct {
 
 	// Time to first byte if applicable.
 	FirstByte *TTFB `json:"first_byte,omitempty"`
+
+	// Average time spent waiting for cache, if applicable.
+	CacheWaitAvgMillis int `json:"cache_wait_avg_millis,omitempty"`
+
+	// Average time until the transfer ended and until the job was completed,
+	// for operations that waited for job completion.
+	IngestAvgMillis  int `json:"ingest_avg_millis,omitempty"`
+	PersistAvgMillis int `json:"persist_avg_millis,omitempty"`
 }
 
 func (r *RequestSizeRange) fill(s bench.SizeSegment) {
@@ -144,6 +163,9 @@ func (r *RequestSizeRange) fill(s bench.SizeSegment) {
 	r.MinSizeString, r.MaxSizeString = s.SizesString()
 	r.AvgObjSize = int(s.Ops.AvgSize())
 	r.AvgDurationMillis = durToMillis(s.Ops.AvgDuration())
+	r.CacheWaitAvgMillis = durToMillis(s.Ops.AvgCacheWait())
+	ingest, persist, _ := s.Ops.PersistLatency()
+	r.IngestAvgMillis, r.PersistAvgMillis = durToMillis(ingest), durToMillis(persist)
 	s.Ops.SortByThroughput()
 	r.BpsAverage = s.Ops.OpThroughput().Float()
 	r.BpsMedian = s.Ops.Median(0.5).BytesPerSec().Float()
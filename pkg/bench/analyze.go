@@ -137,6 +137,75 @@ func (o Operations) TTFB(start, end time.Time) TTFB {
 	return res
 }
 
+// JobPhase contains the time bulk jobs spent in a single phase.
+type JobPhase struct {
+	OpType string
+	// N is the number of phase operations.
+	N      int
+	Errors int
+	// Total is the combined duration of the phase operations.
+	Total time.Duration
+	// PerJob is the average time a job spent in the phase.
+	PerJob time.Duration
+	// Share is the fraction of total job time spent in the phase.
+	// Blobs transferred concurrently can make this exceed 1.
+	Share float64
+}
+
+// JobPhases returns the time spent in each phase of the bulk jobs
+// that have phase operations recorded.
+// Phases are returned in the order they occur in a job.
+func (o Operations) JobPhases() []JobPhase {
+	jobDur := make(map[string]time.Duration)
+	for _, op := range o {
+		if op.JobID != "" && !op.IsJobPhase() {
+			jobDur[op.JobID] = op.Duration()
+		}
+	}
+	phases := make(map[string]*JobPhase)
+	withPhases := make(map[string]struct{})
+	for _, op := range o {
+		if !op.IsJobPhase() {
+			continue
+		}
+		if _, ok := jobDur[op.JobID]; !ok {
+			continue
+		}
+		withPhases[op.JobID] = struct{}{}
+		p := phases[op.OpType]
+		if p == nil {
+			p = &JobPhase{OpType: op.OpType}
+			phases[op.OpType] = p
+		}
+		p.N++
+		if op.Err != "" {
+			p.Errors++
+		}
+		p.Total += op.Duration()
+	}
+	if len(withPhases) == 0 {
+		return nil
+	}
+	var total time.Duration
+	for id := range withPhases {
+		total += jobDur[id]
+	}
+
+	var res []JobPhase
+	for _, typ := range []string{OpJobCreate, OpChunkWait, OpBlobPut, OpBlobGet} {
+		p := phases[typ]
+		if p == nil {
+			continue
+		}
+		p.PerJob = p.Total / time.Duration(len(withPhases))
+		if total > 0 {
+			p.Share = float64(p.Total) / float64(total)
+		}
+		res = append(res, *p)
+	}
+	return res
+}
+
 // OpThroughput returns the average throughput in B/s.
 func (o Operations) OpThroughput() Throughput {
 	var aDur time.Duration
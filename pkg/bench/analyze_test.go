@@ -70,3 +70,66 @@ func TestOperations_Segment(t *testing.T) {
 		t.Log(buf.String())
 	}
 }
+
+func TestOperations_JobPhaseCSV(t *testing.T) {
+	b, err := os.ReadFile("testdata/warp-benchdata-bulkput-phases.csv.zst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = zstdDec.DecodeAll(b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops, err := OperationsFromCSV(bytes.NewBuffer(b), true, 0, 0, t.Logf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Phases overlap the bulk puts they belong to.
+	if !ops.IsMixed() {
+		t.Error("want operations with phases to be mixed")
+	}
+	jobs := ops.FilterByJobPhase(false)
+	if jobs.IsMixed() {
+		t.Error("want operations without phases not to be mixed")
+	}
+	if types := jobs.OpTypes(); len(types) != 1 || types[0] != "BULKPUT" {
+		t.Errorf("want only BULKPUT, got %v", types)
+	}
+	if phases := ops.JobPhases(); len(phases) != 3 {
+		t.Errorf("want 3 job phases, got %+v", phases)
+	}
+}
+
+func TestOperations_JobPhases(t *testing.T) {
+	start := time.Now()
+	at := func(ms int) time.Time {
+		return start.Add(time.Duration(ms) * time.Millisecond)
+	}
+	ops := Operations{
+		{OpType: "BULKPUT", JobID: "a", Start: at(0), End: at(1000)},
+		{OpType: OpJobCreate, JobID: "a", Start: at(0), End: at(100)},
+		{OpType: OpChunkWait, JobID: "a", Start: at(100), End: at(600)},
+		{OpType: OpBlobPut, JobID: "a", Start: at(600), End: at(1000)},
+		{OpType: "BULKPUT", JobID: "b", Start: at(0), End: at(1000)},
+		{OpType: OpJobCreate, JobID: "b", Start: at(0), End: at(300), Err: "failed"},
+		// Not part of a known job.
+		{OpType: OpBlobPut, JobID: "c", Start: at(0), End: at(1000)},
+	}
+	got := ops.JobPhases()
+	want := []JobPhase{
+		{OpType: OpJobCreate, N: 2, Errors: 1, Total: 400 * time.Millisecond, PerJob: 200 * time.Millisecond, Share: 0.2},
+		{OpType: OpChunkWait, N: 1, Total: 500 * time.Millisecond, PerJob: 250 * time.Millisecond, Share: 0.25},
+		{OpType: OpBlobPut, N: 1, Total: 400 * time.Millisecond, PerJob: 200 * time.Millisecond, Share: 0.2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d phases, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("phase %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if n := len(ops.FilterByJobPhase(false)); n != 2 {
+		t.Errorf("got %d job operations, want 2", n)
+	}
+}
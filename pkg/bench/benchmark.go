@@ -19,10 +19,13 @@ package bench
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/SpectraLogic/ds3_go_sdk/ds3"
 	"github.com/SpectraLogic/ds3_go_sdk/ds3/models"
 	"math"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/joshcarter/warp-ds3/pkg/generator"
@@ -52,14 +55,29 @@ type Common struct {
 	Concurrency int
 	Source      func() generator.Source
 	Bucket      string
-	Endpoint    string
 	Locking     bool
 
+	// ClientEndpoint returns the endpoint a client returned by Client connects to.
+	ClientEndpoint func(cl *ds3.Client) string
+
+	// Live receives operations as they are recorded, if set.
+	Live *LiveOps
+
+	// Abort is canceled when the benchmark is aborted.
+	// Unlike the context passed to Start it is not canceled when the benchmark
+	// duration is over, so it bounds work continuing after that,
+	// like waiting for put jobs to persist.
+	Abort context.Context
+
+	// Name or ID of the data policy used when creating the bucket.
+	DataPolicy string
+
 	// Running in client mode.
 	ClientMode bool
 	// Clear bucket before benchmark
 	Clear           bool
 	PrepareProgress chan float64
+	CleanupProgress chan float64
 	// Does destination support versioning?
 	Versioned bool
 
@@ -70,6 +88,9 @@ type Common struct {
 	// Default Put options.
 	PutOpts minio.PutObjectOptions
 
+	// Options for DS3 bulk jobs.
+	BulkOpts BulkOptions
+
 	// Custom is returned to server if set by clients.
 	Custom map[string]string
 
@@ -98,6 +119,29 @@ func (c *Common) GetCommon() *Common {
 	return c
 }
 
+// newCollector returns a collector that also adds operations to Live.
+func (c *Common) newCollector() *Collector {
+	col := NewCollector()
+	col.live = c.Live
+	return col
+}
+
+// abortCtx returns c.Abort or a context that is never canceled if it is not set.
+func (c *Common) abortCtx() context.Context {
+	if c.Abort == nil {
+		return context.Background()
+	}
+	return c.Abort
+}
+
+// endpoint returns the endpoint client connects to.
+func (c *Common) endpoint(client *ds3.Client) string {
+	if c.ClientEndpoint == nil {
+		return ""
+	}
+	return c.ClientEndpoint(client)
+}
+
 // ErrorF formatted error printer
 func (c *Common) ErrorF(format string, data ...interface{}) {
 	c.Error(fmt.Sprintf(format, data...))
@@ -109,6 +153,15 @@ func (c *Common) createEmptyBucket(ctx context.Context) error {
 	cl, done := c.Client()
 	defer done()
 
+	var policyID string
+	if c.DataPolicy != "" {
+		policy, err := c.dataPolicy(cl)
+		if err != nil {
+			return err
+		}
+		policyID = policy.Id
+	}
+
 	headBucketRequest := models.NewHeadBucketRequest(c.Bucket)
 	_, err := cl.HeadBucket(headBucketRequest)
 	if err == nil {
@@ -122,14 +175,19 @@ func (c *Common) createEmptyBucket(ctx context.Context) error {
 		//// won't tell us the difference between "bucket wasn't there" and "bucket
 		//// was there but I couldn't delete it."
 		//cl.DeleteBucketSpectraS3(deleteBucketRequest)
-		return nil // just use existing bucket; don't delete and recreate
+		return c.checkBucketPolicy(cl, policyID) // just use existing bucket; don't delete and recreate
 	}
 
 	console.Eraseline()
 	console.Infof("\rCreating Bucket %q...", c.Bucket)
 
-	putBucketRequest := models.NewPutBucketRequest(c.Bucket)
-	_, err = cl.PutBucket(putBucketRequest)
+	if policyID != "" {
+		putBucketRequest := models.NewPutBucketSpectraS3Request(c.Bucket).WithDataPolicyId(policyID)
+		_, err = cl.PutBucketSpectraS3(putBucketRequest)
+	} else {
+		putBucketRequest := models.NewPutBucketRequest(c.Bucket)
+		_, err = cl.PutBucket(putBucketRequest)
+	}
 
 	// In client mode someone else may have created it first.
 	// Check if it exists now.
@@ -140,19 +198,151 @@ func (c *Common) createEmptyBucket(ctx context.Context) error {
 		if err2 != nil {
 			return err // return original error
 		}
+		return c.checkBucketPolicy(cl, policyID)
 	}
 
 	return nil
 }
 
+// dataPolicy looks up the data policy named by DataPolicy.
+// Policies can be referenced by name or ID.
+func (c *Common) dataPolicy(cl *ds3.Client) (*models.DataPolicy, error) {
+	resp, err := cl.GetDataPolicySpectraS3(models.NewGetDataPolicySpectraS3Request(c.DataPolicy))
+	if err != nil {
+		var bsc *models.BadStatusCodeError
+		if errors.As(err, &bsc) && bsc.ActualStatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("data policy %q does not exist", c.DataPolicy)
+		}
+		return nil, fmt.Errorf("looking up data policy %q: %w", c.DataPolicy, err)
+	}
+	return &resp.DataPolicy, nil
+}
+
+// checkBucketPolicy returns an error if the existing bucket
+// does not use the data policy with the given ID.
+// No check is done if policyID is empty.
+func (c *Common) checkBucketPolicy(cl *ds3.Client, policyID string) error {
+	if policyID == "" {
+		return nil
+	}
+	resp, err := cl.GetBucketSpectraS3(models.NewGetBucketSpectraS3Request(c.Bucket))
+	if err != nil {
+		return fmt.Errorf("looking up data policy of bucket %q: %w", c.Bucket, err)
+	}
+	if resp.Bucket.DataPolicyId != policyID {
+		return fmt.Errorf("bucket %q exists with data policy %s, not %q (%s). Use another bucket or delete it first", c.Bucket, resp.Bucket.DataPolicyId, c.DataPolicy, policyID)
+	}
+	return nil
+}
+
 // prepareProgress updates preparation progess with the value 0->1.
 func (c *Common) prepareProgress(progress float64) {
-	if c.PrepareProgress == nil {
+	sendProgress(c.PrepareProgress, progress)
+}
+
+// cleanupProgress reports cleanup progress in the range 0 -> 1.
+func (c *Common) cleanupProgress(progress float64) {
+	sendProgress(c.CleanupProgress, progress)
+}
+
+func sendProgress(ch chan float64, progress float64) {
+	if ch == nil {
 		return
 	}
 	progress = math.Max(0, math.Min(1, progress))
 	select {
-	case c.PrepareProgress <- progress:
+	case ch <- progress:
 	default:
 	}
 }
+
+// deleteObjects deletes the named objects from the bucket using multi-object deletes.
+// Objects that no longer exist are ignored and other failures are logged.
+func (c *Common) deleteObjects(ctx context.Context, names []string) {
+	const batchSize = 1000
+	if len(names) == 0 {
+		return
+	}
+	console.Eraseline()
+	console.Infof("\rDeleting %d objects...", len(names))
+
+	batches := make(chan []string, len(names)/batchSize+1)
+	for len(names) > 0 {
+		n := batchSize
+		if n > len(names) {
+			n = len(names)
+		}
+		batches <- names[:n]
+		names = names[n:]
+	}
+	total := len(batches)
+	close(batches)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var done int
+	wg.Add(c.Concurrency)
+	for i := 0; i < c.Concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				if ctx.Err() != nil {
+					return
+				}
+				cl, cldone := c.Client()
+				resp, err := cl.DeleteObjects(models.NewDeleteObjectsRequest(c.Bucket, batch))
+				cldone()
+				if err != nil {
+					c.Error("delete error: ", err)
+				} else {
+					for _, e := range resp.DeleteResult.Errors {
+						if e.Code != nil && *e.Code == "NoSuchKey" {
+							continue
+						}
+						c.Error("delete error: ", stringOrEmpty(e.Key), ": ", stringOrEmpty(e.Message))
+					}
+				}
+				mu.Lock()
+				done++
+				c.cleanupProgress(float64(done) / float64(total))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// objectNames returns the names of objs.
+func objectNames(objs generator.Objects) []string {
+	names := make([]string, len(objs))
+	for i, obj := range objs {
+		names[i] = obj.Name
+	}
+	return names
+}
+
+// createdObjects records the names of objects created by a benchmark,
+// so they can be deleted on cleanup.
+type createdObjects struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func (o *createdObjects) add(names ...string) {
+	o.mu.Lock()
+	o.names = append(o.names, names...)
+	o.mu.Unlock()
+}
+
+func (o *createdObjects) get() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.names
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
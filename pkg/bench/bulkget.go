@@ -0,0 +1,205 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/SpectraLogic/ds3_go_sdk/ds3"
+	"github.com/SpectraLogic/ds3_go_sdk/ds3/models"
+	"github.com/joshcarter/warp-ds3/pkg/generator"
+)
+
+// BulkGet benchmarks download speed through DS3 bulk get jobs.
+type BulkGet struct {
+	Common
+	BulkNum       int
+	CreateObjects int
+	objects       generator.Objects
+}
+
+// Prepare will create an empty bucket or delete any content already there
+// and upload a number of objects using bulk put jobs.
+func (g *BulkGet) Prepare(ctx context.Context) error {
+	if err := g.createEmptyBucket(ctx); err != nil {
+		return err
+	}
+	var err error
+	g.objects, _, err = g.uploadBulkObjects(ctx, g.CreateObjects, g.BulkNum)
+	return err
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+func (g *BulkGet) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var wg sync.WaitGroup
+	wg.Add(g.Concurrency)
+	c := g.newCollector()
+	if g.AutoTermDur > 0 {
+		ctx = c.AutoTerm(ctx, "BULKGET", g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
+	}
+	bulkNum := g.BulkNum
+	if bulkNum > len(g.objects) {
+		bulkNum = len(g.objects)
+	}
+
+	for i := 0; i < g.Concurrency; i++ {
+		go func(i int) {
+			rng := rand.New(rand.NewSource(int64(i)))
+			rcv := c.Receiver()
+			defer wg.Done()
+			done := ctx.Done()
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				names := make([]string, bulkNum)
+				totalSize := int64(0)
+				for j, idx := range rng.Perm(len(g.objects))[:bulkNum] {
+					names[j] = g.objects[idx].Name
+					totalSize += g.objects[idx].Size
+				}
+
+				op := Operation{
+					OpType:   "BULKGET",
+					Thread:   uint16(i),
+					Size:     totalSize,
+					File:     names[0],
+					ObjPerOp: bulkNum,
+				}
+				client, cldone := g.Client()
+				op.Endpoint = g.endpoint(client)
+				op.Start = time.Now()
+				n, phases, err := g.getBulkObjects(ctx, client, names, &op)
+				op.End = time.Now()
+				cldone()
+				if ctx.Err() != nil {
+					// The benchmark ended while the job was in progress.
+					return
+				}
+				if err != nil {
+					g.Error("bulk get error: ", err)
+					op.Err = err.Error()
+				}
+				if n != op.Size && op.Err == "" {
+					op.Err = fmt.Sprint("unexpected download size. want:", op.Size, ", got:", n)
+					g.Error(op.Err)
+				}
+				rcv <- op
+				for _, phase := range phases {
+					rcv <- phase
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	return c.Close(), nil
+}
+
+// getBulkObjects downloads the named objects through a single DS3 bulk get job
+// and returns the number of bytes received.
+// The time the first byte of the job was received is stored in op.FirstByte
+// and time spent waiting for data to be staged is added to op.CacheWait.
+// If phase operations are enabled, they are returned for the caller to record.
+// Up to BulkOpts.Streams blobs are downloaded concurrently.
+func (c *Common) getBulkObjects(ctx context.Context, client *ds3.Client, names []string, op *Operation) (received int64, phases Operations, err error) {
+	rec := c.newPhaseRecorder(op)
+	defer func() {
+		phases = rec.operations()
+	}()
+
+	start := time.Now()
+	getBulkResponse, err := client.GetBulkJobSpectraS3(c.BulkOpts.getBulkRequest(c.Bucket, names))
+	if err != nil {
+		rec.record(OpJobCreate, start, len(names), 0, names[0], err)
+		return 0, phases, fmt.Errorf("creating job: %w", err)
+	}
+	jobID := getBulkResponse.MasterObjectList.JobId
+	op.JobID = jobID
+	rec.record(OpJobCreate, start, len(names), 0, names[0], nil)
+	defer func() {
+		if err != nil {
+			c.failJob(client, jobID)
+		}
+	}()
+	totalChunkCount := len(getBulkResponse.MasterObjectList.Objects)
+	processed := make(map[string]struct{}, totalChunkCount)
+
+	var mu sync.Mutex
+	for len(processed) < totalChunkCount {
+		chunksReadyResponse, err := c.waitForReadyChunks(ctx, client, jobID, op, rec)
+		if err != nil {
+			return received, phases, err
+		}
+		err = c.transferBlobs(chunksReadyResponse.MasterObjectList.Objects, processed, func(blob models.BulkObject) error {
+			var n int64
+			var firstByte *time.Time
+			start := time.Now()
+			err := c.BulkOpts.retry(ctx, func() error {
+				getObjRequest := models.NewGetObjectRequest(c.Bucket, *blob.Name).
+					WithJob(jobID).
+					WithOffset(blob.Offset)
+				getObjResponse, err := client.GetObject(getObjRequest)
+				if err != nil {
+					return err
+				}
+				defer getObjResponse.Content.Close()
+				fbr := firstByteRecorder{r: getObjResponse.Content}
+				n, err = io.Copy(io.Discard, &fbr)
+				firstByte = fbr.t
+				return err
+			})
+			if err == nil && n != blob.Length {
+				err = fmt.Errorf("unexpected blob size. want: %d, got: %d", blob.Length, n)
+			}
+			mu.Lock()
+			received += n
+			if firstByte != nil && (op.FirstByte == nil || firstByte.Before(*op.FirstByte)) {
+				op.FirstByte = firstByte
+			}
+			mu.Unlock()
+			rec.record(OpBlobGet, start, 1, n, *blob.Name, err)
+			if err != nil {
+				return fmt.Errorf("job %s: getting %s at offset %d: %w", jobID, *blob.Name, blob.Offset, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return received, phases, err
+		}
+	}
+	return received, phases, nil
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+func (g *BulkGet) Cleanup(ctx context.Context) {
+	g.deleteObjects(ctx, objectNames(g.objects))
+}
+
+// firstByteRecorder records the time the first byte was read.
+// If t is already set it is left untouched.
+type firstByteRecorder struct {
+	t *time.Time
+	r io.Reader
+}
+
+func (f *firstByteRecorder) Read(p []byte) (n int, err error) {
+	if f.t != nil || len(p) == 0 {
+		return f.r.Read(p)
+	}
+	// Read a single byte.
+	n, err = f.r.Read(p[:1])
+	if n > 0 {
+		t := time.Now()
+		f.t = &t
+	}
+	return n, err
+}
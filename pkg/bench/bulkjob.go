@@ -0,0 +1,411 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/SpectraLogic/ds3_go_sdk/ds3"
+	"github.com/SpectraLogic/ds3_go_sdk/ds3/models"
+)
+
+// Operation types recording the phases of a bulk job.
+// They are emitted next to the operation covering the whole job
+// and share its JobID.
+const (
+	OpJobCreate = "JOB_CREATE"
+	OpChunkWait = "CHUNK_WAIT"
+	OpBlobPut   = "BLOB_PUT"
+	OpBlobGet   = "BLOB_GET"
+)
+
+// BulkOptions contains options for DS3 bulk jobs.
+type BulkOptions struct {
+	// Retries is the number of times a failed chunk poll or blob
+	// transfer is retried before the job is given up.
+	Retries int
+	// RetryBackoff is the wait before the first retry.
+	// It is doubled for every following attempt.
+	RetryBackoff time.Duration
+	// AbandonFailed leaves failed jobs on the server
+	// instead of cancelling them.
+	AbandonFailed bool
+
+	// MinChunkWait and MaxChunkWait bound the wait before polling for
+	// ready chunks again when the server has none available.
+	// Within the bounds the Retry-After returned by the server is used.
+	MinChunkWait time.Duration
+	MaxChunkWait time.Duration
+
+	// PhaseOps records job creation, chunk waits and blob
+	// transfers as separate operations.
+	PhaseOps bool
+
+	// Streams is the number of blobs of a job transferred concurrently.
+	Streams int
+
+	// JobPollInterval is the wait between job status polls
+	// when waiting for the server to complete a job.
+	JobPollInterval time.Duration
+	// WaitPersist keeps polling put jobs after the transfer until the server
+	// has completed them, recording the time as the operation's PersistEnd.
+	// Jobs are polled in the background, so workers continue with the next job.
+	WaitPersist bool
+	// PersistTimeout limits how long a job is polled for completion.
+	// Jobs not completed in time are recorded without PersistEnd.
+	PersistTimeout time.Duration
+
+	// MaxUploadSize is the largest blob objects of put jobs are split into.
+	// When 0 the server default is used.
+	MaxUploadSize int64
+	// Priority of created jobs.
+	// When undefined the priority of the data policy is used.
+	Priority models.Priority
+	// Aggregating, MinimizeSpanning and VerifyAfterWrite are sent
+	// with created jobs when set. Otherwise the server default is used.
+	// MinimizeSpanning and VerifyAfterWrite only apply to put jobs.
+	Aggregating      *bool
+	MinimizeSpanning *bool
+	VerifyAfterWrite *bool
+}
+
+// String returns a description of the job settings.
+func (o BulkOptions) String() string {
+	optional := func(b *bool) string {
+		if b == nil {
+			return "default"
+		}
+		return strconv.FormatBool(*b)
+	}
+	priority := "default"
+	if o.Priority != models.UNDEFINED {
+		priority = o.Priority.String()
+	}
+	blobSize := "default"
+	if o.MaxUploadSize > 0 {
+		blobSize = strconv.FormatInt(o.MaxUploadSize, 10)
+	}
+	return fmt.Sprintf("Bulk jobs: priority=%s, aggregating=%s, minimize-spanning=%s, verify-after-write=%s, max-upload-size=%s, streams=%d, wait-persist=%t",
+		priority, optional(o.Aggregating), optional(o.MinimizeSpanning), optional(o.VerifyAfterWrite), blobSize, o.Streams, o.WaitPersist)
+}
+
+// putBulkRequest returns a request creating a put job for objs.
+func (o BulkOptions) putBulkRequest(bucket string, objs []models.Ds3PutObject) *models.PutBulkJobSpectraS3Request {
+	req := models.NewPutBulkJobSpectraS3Request(bucket, objs)
+	if o.MaxUploadSize > 0 {
+		req = req.WithMaxUploadSize(o.MaxUploadSize)
+	}
+	if o.Aggregating != nil {
+		req = req.WithAggregating(*o.Aggregating)
+	}
+	if o.MinimizeSpanning != nil {
+		req = req.WithMinimizeSpanningAcrossMedia(*o.MinimizeSpanning)
+	}
+	if o.VerifyAfterWrite != nil {
+		req = req.WithVerifyAfterWrite(*o.VerifyAfterWrite)
+	}
+	if o.Priority != models.UNDEFINED {
+		req = req.WithPriority(o.Priority)
+	}
+	return req
+}
+
+// getBulkRequest returns a request creating a get job for the named objects.
+func (o BulkOptions) getBulkRequest(bucket string, names []string) *models.GetBulkJobSpectraS3Request {
+	req := models.NewGetBulkJobSpectraS3Request(bucket, names)
+	if o.Aggregating != nil {
+		req = req.WithAggregating(*o.Aggregating)
+	}
+	if o.Priority != models.UNDEFINED {
+		req = req.WithPriority(o.Priority)
+	}
+	return req
+}
+
+// retry calls fn until it succeeds, returns an error that is not worth
+// retrying or the configured number of retries is exhausted.
+func (o BulkOptions) retry(ctx context.Context, fn func() error) error {
+	backoff := o.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= o.Retries || !retryable(err) {
+			return err
+		}
+		if _, err := sleepCtx(ctx, backoff); err != nil {
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// retryable returns whether err may be transient.
+// Requests rejected by the server with a 4xx status will fail again.
+func retryable(err error) bool {
+	var bsc *models.BadStatusCodeError
+	if errors.As(err, &bsc) {
+		return bsc.ActualStatusCode >= 500
+	}
+	return true
+}
+
+// chunkWait returns how long to wait before polling for ready chunks again.
+// The Retry-After header is used if present, clamped to the configured bounds.
+// Without it the maximum wait is used.
+func (o BulkOptions) chunkWait(h *http.Header) time.Duration {
+	wait := o.MaxChunkWait
+	if h != nil {
+		if secs, err := strconv.Atoi(h.Get("Retry-After")); err == nil && secs >= 0 {
+			wait = time.Duration(secs) * time.Second
+		}
+	}
+	if wait > o.MaxChunkWait {
+		wait = o.MaxChunkWait
+	}
+	if wait < o.MinChunkWait {
+		wait = o.MinChunkWait
+	}
+	return wait
+}
+
+// waitForChunks waits before the job is polled for ready chunks again.
+// The time actually waited is returned.
+// If ctx is canceled the wait is cut short and the context error is returned.
+func (o BulkOptions) waitForChunks(ctx context.Context, resp *models.GetJobChunksReadyForClientProcessingSpectraS3Response) (time.Duration, error) {
+	return sleepCtx(ctx, o.chunkWait(resp.Headers))
+}
+
+// waitForReadyChunks polls the job until the server has chunks ready for the client.
+// Time spent waiting is added to op.CacheWait and recorded as a CHUNK_WAIT phase.
+func (c *Common) waitForReadyChunks(ctx context.Context, client *ds3.Client, jobID string, op *Operation, rec *phaseRecorder) (resp *models.GetJobChunksReadyForClientProcessingSpectraS3Response, err error) {
+	start := time.Now()
+	defer func() {
+		rec.record(OpChunkWait, start, 0, 0, op.File, err)
+	}()
+	for {
+		err := c.BulkOpts.retry(ctx, func() (err error) {
+			chunksReady := models.NewGetJobChunksReadyForClientProcessingSpectraS3Request(jobID)
+			resp, err = client.GetJobChunksReadyForClientProcessingSpectraS3(chunksReady)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("job %s: getting ready chunks: %w", jobID, err)
+		}
+		if len(resp.MasterObjectList.Objects) > 0 {
+			return resp, nil
+		}
+		// When no chunks are returned we need to wait for the server
+		// to free or allocate cache.
+		waited, err := c.BulkOpts.waitForChunks(ctx, resp)
+		op.CacheWait += waited
+		if err != nil {
+			return nil, fmt.Errorf("job %s: waiting for chunks: %w", jobID, err)
+		}
+	}
+}
+
+// verifyBulkRequest returns a request creating a verify job for the named objects.
+func (o BulkOptions) verifyBulkRequest(bucket string, names []string) *models.VerifyBulkJobSpectraS3Request {
+	req := models.NewVerifyBulkJobSpectraS3Request(bucket, names)
+	if o.Aggregating != nil {
+		req = req.WithAggregating(*o.Aggregating)
+	}
+	if o.Priority != models.UNDEFINED {
+		req = req.WithPriority(o.Priority)
+	}
+	return req
+}
+
+// waitForJob polls the job status until the server has completed the job.
+// The final job status is returned. A canceled job returns an error.
+func (c *Common) waitForJob(ctx context.Context, client *ds3.Client, jobID string) (*models.MasterObjectList, error) {
+	for {
+		var resp *models.GetJobSpectraS3Response
+		err := c.BulkOpts.retry(ctx, func() (err error) {
+			resp, err = client.GetJobSpectraS3(models.NewGetJobSpectraS3Request(jobID))
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("job %s: getting status: %w", jobID, err)
+		}
+		switch resp.MasterObjectList.Status {
+		case models.JOB_STATUS_COMPLETED:
+			return &resp.MasterObjectList, nil
+		case models.JOB_STATUS_CANCELED:
+			return &resp.MasterObjectList, fmt.Errorf("job %s was canceled", jobID)
+		}
+		if _, err := sleepCtx(ctx, c.BulkOpts.JobPollInterval); err != nil {
+			return nil, fmt.Errorf("job %s: waiting for completion: %w", jobID, err)
+		}
+	}
+}
+
+// persistTracker waits for the jobs of put operations to be completed
+// by the server in the background.
+type persistTracker struct {
+	wg sync.WaitGroup
+}
+
+// send sends op to rcv. If BulkOpts.WaitPersist is set, the job of op is polled
+// in the background and op is sent once the job is completed, with PersistEnd set,
+// or once BulkOpts.PersistTimeout has passed or c.Abort is canceled.
+// Polling errors are logged but don't fail op, since the upload succeeded.
+func (p *persistTracker) send(c *Common, client *ds3.Client, op Operation, rcv chan<- Operation) {
+	if !c.BulkOpts.WaitPersist || op.JobID == "" || op.Err != "" {
+		rcv <- op
+		return
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ctx, cancel := context.WithTimeout(c.abortCtx(), c.BulkOpts.PersistTimeout)
+		defer cancel()
+		if _, err := c.waitForJob(ctx, client, op.JobID); err == nil {
+			t := time.Now()
+			op.PersistEnd = &t
+		} else if ctx.Err() == nil {
+			c.Error("persist error: ", err)
+		}
+		rcv <- op
+	}()
+}
+
+// wait waits until all operations passed to send have been sent.
+func (p *persistTracker) wait() {
+	p.wg.Wait()
+}
+
+// transferBlobs calls fn for every blob of the chunks that have not been processed yet,
+// running up to BulkOpts.Streams calls concurrently.
+// Chunks are marked as processed once all their blobs are transferred.
+// After the first failure no more blobs are started and the error is returned.
+func (c *Common) transferBlobs(chunks []models.Objects, processed map[string]struct{}, fn func(blob models.BulkObject) error) error {
+	streams := c.BulkOpts.Streams
+	if streams < 1 {
+		streams = 1
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+	blobs := make(chan models.BulkObject)
+	wg.Add(streams)
+	for i := 0; i < streams; i++ {
+		go func() {
+			defer wg.Done()
+			for blob := range blobs {
+				if err := fn(blob); err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	var started []string
+queue:
+	for _, chunk := range chunks {
+		if _, ok := processed[chunk.ChunkId]; ok {
+			continue
+		}
+		for _, blob := range chunk.Objects {
+			if failed() {
+				break queue
+			}
+			blobs <- blob
+		}
+		started = append(started, chunk.ChunkId)
+	}
+	close(blobs)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	for _, id := range started {
+		processed[id] = struct{}{}
+	}
+	return nil
+}
+
+// sleepCtx sleeps for d or until ctx is canceled.
+// The time actually slept is returned.
+func sleepCtx(ctx context.Context, d time.Duration) (time.Duration, error) {
+	start := time.Now()
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return time.Since(start), ctx.Err()
+	case <-t.C:
+		return time.Since(start), nil
+	}
+}
+
+// failJob cancels a failed job, unless failed jobs should be abandoned.
+func (c *Common) failJob(client *ds3.Client, jobID string) {
+	if c.BulkOpts.AbandonFailed {
+		return
+	}
+	if _, err := client.CancelJobSpectraS3(models.NewCancelJobSpectraS3Request(jobID)); err != nil {
+		c.Error("cancel job ", jobID, " error: ", err)
+	}
+}
+
+// phaseRecorder collects the phase operations of a single bulk job.
+// A nil recorder discards everything.
+type phaseRecorder struct {
+	parent *Operation
+	mu     sync.Mutex
+	ops    Operations
+}
+
+// newPhaseRecorder returns a recorder for phases of the job described by parent.
+// If phase operations are disabled, nil is returned.
+func (c *Common) newPhaseRecorder(parent *Operation) *phaseRecorder {
+	if !c.BulkOpts.PhaseOps {
+		return nil
+	}
+	return &phaseRecorder{parent: parent}
+}
+
+// record adds a phase that started at start and ended now.
+func (r *phaseRecorder) record(typ string, start time.Time, objs int, size int64, file string, err error) {
+	if r == nil {
+		return
+	}
+	op := Operation{
+		OpType:   typ,
+		ObjPerOp: objs,
+		Start:    start,
+		End:      time.Now(),
+		Size:     size,
+		File:     file,
+		Thread:   r.parent.Thread,
+		Endpoint: r.parent.Endpoint,
+		JobID:    r.parent.JobID,
+	}
+	if err != nil {
+		op.Err = err.Error()
+	}
+	r.mu.Lock()
+	r.ops = append(r.ops, op)
+	r.mu.Unlock()
+}
+
+// operations returns the recorded phases.
+func (r *phaseRecorder) operations() Operations {
+	if r == nil {
+		return nil
+	}
+	return r.ops
+}
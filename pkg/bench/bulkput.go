@@ -2,12 +2,13 @@ package bench
 
 import (
 	"context"
+	"fmt"
+	"github.com/SpectraLogic/ds3_go_sdk/ds3"
 	"github.com/SpectraLogic/ds3_go_sdk/ds3/models"
 	"github.com/SpectraLogic/ds3_go_sdk/helpers"
 	"github.com/joshcarter/warp-ds3/pkg/generator"
+	"github.com/minio/pkg/console"
 	"io"
-	"log"
-	"net/http"
 	"sync"
 	"time"
 )
@@ -15,6 +16,11 @@ import (
 type BulkPut struct {
 	Common
 	BulkNum int
+	// CompareNaked interleaves bulk jobs with batches of naked puts
+	// of the same objects count and size, recorded as NAKEDPUT.
+	CompareNaked bool
+	created      createdObjects
+	persist      persistTracker
 }
 
 // Prepare will create an empty bucket ot delete any content already there.
@@ -27,9 +33,9 @@ func (u *BulkPut) Prepare(ctx context.Context) error {
 func (u *BulkPut) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
 	var wg sync.WaitGroup
 	wg.Add(u.Concurrency)
-	c := NewCollector()
+	c := u.newCollector()
 	if u.AutoTermDur > 0 {
-		ctx = c.AutoTerm(ctx, http.MethodPut, u.AutoTermScale, autoTermCheck, autoTermSamples, u.AutoTermDur)
+		ctx = c.AutoTerm(ctx, "BULKPUT", u.AutoTermScale, autoTermCheck, autoTermSamples, u.AutoTermDur)
 	}
 
 	for i := 0; i < u.Concurrency; i++ {
@@ -37,6 +43,8 @@ func (u *BulkPut) Start(ctx context.Context, wait chan struct{}) (Operations, er
 			rcv := c.Receiver()
 			defer wg.Done()
 			done := ctx.Done()
+			// Alternate which path threads start with to keep the load balanced.
+			naked := u.CompareNaked && i%2 == 1
 
 			<-wait
 			for {
@@ -46,96 +54,243 @@ func (u *BulkPut) Start(ctx context.Context, wait chan struct{}) (Operations, er
 				default:
 				}
 
-				objs := make(map[string]*generator.Object, u.BulkNum)
-				ds3objs := make([]models.Ds3PutObject, u.BulkNum)
+				objs := make([]*generator.Object, u.BulkNum)
 				totalSize := int64(0)
-
-				for j := 0; j < u.BulkNum; j++ {
-					obj := u.Source().Object() // Create a new generator for each object
-					objs[obj.Name] = obj
-					ds3objs[j] = models.Ds3PutObject{obj.Name, obj.Size}
-					totalSize += obj.Size
+				for j := range objs {
+					objs[j] = u.Source().Object() // Create a new generator for each object
+					totalSize += objs[j].Size
 				}
 
 				op := Operation{
 					OpType:   "BULKPUT",
 					Thread:   uint16(i),
 					Size:     totalSize,
-					File:     ds3objs[0].Name,
+					File:     objs[0].Name,
 					ObjPerOp: u.BulkNum,
-					Endpoint: u.Endpoint,
 				}
 				op.Start = time.Now()
 				client, cldone := u.Client()
-
-				putBulkRequest := models.NewPutBulkJobSpectraS3Request(u.Bucket, ds3objs)
-
-				putBulkResponse, err := client.PutBulkJobSpectraS3(putBulkRequest)
+				op.Endpoint = u.endpoint(client)
+				var phases Operations
+				var err error
+				if naked {
+					op.OpType = "NAKEDPUT"
+					var n int64
+					n, err = u.putNakedObjects(client, objs)
+					if err == nil && n != op.Size {
+						err = fmt.Errorf("unexpected upload size. want: %d, got: %d", op.Size, n)
+					}
+				} else {
+					phases, err = u.putBulkObjects(ctx, client, objs, &op)
+				}
+				op.End = time.Now()
+				cldone()
+				if u.CompareNaked {
+					naked = !naked
+				}
+				if op.OpType == "NAKEDPUT" || op.JobID != "" {
+					// Failed uploads may have left some objects behind.
+					for _, obj := range objs {
+						u.created.add(obj.Name)
+					}
+				}
+				if err != nil && ctx.Err() != nil {
+					// The benchmark ended while the job was in progress.
+					return
+				}
 				if err != nil {
-					u.Error("put bulk error: ", err)
+					u.Error("upload error: ", err)
 					op.Err = err.Error()
 				}
+				u.persist.send(&u.Common, client, op, rcv)
+				for _, phase := range phases {
+					rcv <- phase
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	u.persist.wait()
+	return c.Close(), nil
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+func (u *BulkPut) Cleanup(ctx context.Context) {
+	u.deleteObjects(ctx, u.created.get())
+}
 
-				totalChunkCount := len(putBulkResponse.MasterObjectList.Objects)
-				curChunkCount := 0
+// putNakedObjects uploads objs with naked puts, each creating an implicit job,
+// and returns the number of bytes sent.
+// Up to BulkOpts.Streams objects are uploaded concurrently.
+func (c *Common) putNakedObjects(client *ds3.Client, objs []*generator.Object) (sent int64, err error) {
+	streams := c.BulkOpts.Streams
+	if streams < 1 {
+		streams = 1
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	queue := make(chan *generator.Object, len(objs))
+	for _, obj := range objs {
+		queue <- obj
+	}
+	close(queue)
+	wg.Add(streams)
+	for i := 0; i < streams; i++ {
+		go func() {
+			defer wg.Done()
+			for obj := range queue {
+				n, putErr := putObject(client, c.Bucket, obj)
+				mu.Lock()
+				sent += n
+				if putErr != nil && err == nil {
+					err = fmt.Errorf("putting %s: %w", obj.Name, putErr)
+				}
+				failed := err != nil
+				mu.Unlock()
+				if failed {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return sent, err
+}
 
-				for curChunkCount < totalChunkCount {
-					// fmt.Printf("chunk %d of %d\n", curChunkCount+1, totalChunkCount)
+// uploadBulkObjects uploads n objects using bulk put jobs of up to bulkNum objects
+// with Concurrency jobs running at once.
+// The uploaded objects are returned without readers, along with the IDs of the jobs.
+func (c *Common) uploadBulkObjects(ctx context.Context, n, bulkNum int) (generator.Objects, []string, error) {
+	src := c.Source()
+	console.Eraseline()
+	console.Info("\rUploading ", n, " objects of ", src.String())
 
-					// Get the list of available chunks that the server can receive. The server may
-					// not be able to receive everything, so not all chunks will necessarily be
-					// returned
-					chunksReady := models.NewGetJobChunksReadyForClientProcessingSpectraS3Request(putBulkResponse.MasterObjectList.JobId)
-					chunksReadyResponse, err := client.GetJobChunksReadyForClientProcessingSpectraS3(chunksReady)
-					if err != nil {
-						log.Fatal(err)
-					}
+	batches := make(chan int, n/bulkNum+1)
+	for left := n; left > 0; left -= bulkNum {
+		if left < bulkNum {
+			batches <- left
+		} else {
+			batches <- bulkNum
+		}
+	}
+	close(batches)
 
-					// Check to see if any chunks can be processed
-					numberOfChunks := len(chunksReadyResponse.MasterObjectList.Objects)
-					if numberOfChunks > 0 {
-						// fmt.Printf("number of chunks: %d\n", numberOfChunks)
-
-						// Loop through all the chunks that are available for processing, and send
-						// the files that are contained within them.
-						for _, curChunk := range chunksReadyResponse.MasterObjectList.Objects {
-							for _, curObj := range curChunk.Objects {
-								// fmt.Printf("- chunk %d of %d, object %d of %d\n", x+1, len(chunksReadyResponse.MasterObjectList.Objects), y+1, len(curChunk.Objects))
-								r := objs[*curObj.Name].Reader
-								r.Seek(curObj.Offset, io.SeekStart)
-								reader := helpers.NewIoReaderWithSizeDecorator(r, curObj.Length)
-
-								// fmt.Printf("- sending '%s' offset %d length %d\n", *curObj.Name, curObj.Offset, curObj.Length)
-								putObjRequest := models.NewPutObjectRequest(u.Bucket, *curObj.Name, reader).
-									WithJob(chunksReadyResponse.MasterObjectList.JobId).
-									WithOffset(curObj.Offset)
-
-								_, err = client.PutObject(putObjRequest)
-								if err != nil {
-									op.Err = err.Error()
-									log.Fatal(err)
-								}
-								// fmt.Printf("Sent: %s offset=%d length=%d\n", *curObj.Name, curObj.Offset, curObj.Length)
-							}
-							curChunkCount++
-						}
-					} else {
-						// When no chunks are returned we need to sleep to allow for cache space to
-						// be freed.
-						time.Sleep(time.Second * 5)
-					}
+	var wg sync.WaitGroup
+	var groupErr error
+	var mu sync.Mutex
+	uploaded := make(generator.Objects, 0, n)
+	var jobs []string
+	wg.Add(c.Concurrency)
+	for i := 0; i < c.Concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for n := range batches {
+				select {
+				case <-ctx.Done():
+					return
+				default:
 				}
-				op.End = time.Now()
+				objs := make([]*generator.Object, n)
+				for j := range objs {
+					objs[j] = c.Source().Object() // Create a new generator for each object
+				}
+				client, cldone := c.Client()
+				var op Operation
+				_, err := c.putBulkObjects(ctx, client, objs, &op)
 				cldone()
-				rcv <- op
+				if err != nil {
+					err := fmt.Errorf("bulk upload error: %w", err)
+					c.Error(err)
+					mu.Lock()
+					if groupErr == nil {
+						groupErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				mu.Lock()
+				for _, obj := range objs {
+					obj.Reader = nil
+					uploaded = append(uploaded, *obj)
+				}
+				jobs = append(jobs, op.JobID)
+				c.prepareProgress(float64(len(uploaded)) / float64(cap(uploaded)))
+				mu.Unlock()
 			}
-		}(i)
+		}()
 	}
 	wg.Wait()
-	return c.Close(), nil
+	return uploaded, jobs, groupErr
 }
 
-// Cleanup deletes everything uploaded to the bucket.
-func (u *BulkPut) Cleanup(context.Context) {
-	// u.deleteAllInBucket(ctx)
+// putBulkObjects uploads objs through a single DS3 bulk put job.
+// Up to BulkOpts.Streams blobs are uploaded concurrently,
+// but blobs of the same object are uploaded one at a time.
+// Time spent waiting for the server to allocate cache is added to op.CacheWait.
+// If phase operations are enabled, they are returned for the caller to record.
+// Jobs that fail after creation are cancelled unless AbandonFailed is set.
+func (c *Common) putBulkObjects(ctx context.Context, client *ds3.Client, objs []*generator.Object, op *Operation) (phases Operations, err error) {
+	byName := make(map[string]*generator.Object, len(objs))
+	locks := make(map[string]*sync.Mutex, len(objs))
+	ds3objs := make([]models.Ds3PutObject, len(objs))
+	for i, obj := range objs {
+		byName[obj.Name] = obj
+		locks[obj.Name] = &sync.Mutex{}
+		ds3objs[i] = models.Ds3PutObject{Name: obj.Name, Size: obj.Size}
+	}
+	rec := c.newPhaseRecorder(op)
+	defer func() {
+		phases = rec.operations()
+	}()
+
+	start := time.Now()
+	putBulkResponse, err := client.PutBulkJobSpectraS3(c.BulkOpts.putBulkRequest(c.Bucket, ds3objs))
+	if err != nil {
+		rec.record(OpJobCreate, start, len(objs), 0, ds3objs[0].Name, err)
+		return phases, fmt.Errorf("creating job: %w", err)
+	}
+	jobID := putBulkResponse.MasterObjectList.JobId
+	op.JobID = jobID
+	rec.record(OpJobCreate, start, len(objs), 0, ds3objs[0].Name, nil)
+	defer func() {
+		if err != nil {
+			c.failJob(client, jobID)
+		}
+	}()
+	totalChunkCount := len(putBulkResponse.MasterObjectList.Objects)
+	processed := make(map[string]struct{}, totalChunkCount)
+
+	for len(processed) < totalChunkCount {
+		chunksReadyResponse, err := c.waitForReadyChunks(ctx, client, jobID, op, rec)
+		if err != nil {
+			return phases, err
+		}
+		err = c.transferBlobs(chunksReadyResponse.MasterObjectList.Objects, processed, func(blob models.BulkObject) error {
+			// Blobs of the same object share its reader.
+			obj := byName[*blob.Name]
+			mu := locks[*blob.Name]
+			mu.Lock()
+			defer mu.Unlock()
+			start := time.Now()
+			err := c.BulkOpts.retry(ctx, func() error {
+				if _, err := obj.Reader.Seek(blob.Offset, io.SeekStart); err != nil {
+					return err
+				}
+				putObjRequest := models.NewPutObjectRequest(c.Bucket, *blob.Name, helpers.NewIoReaderWithSizeDecorator(obj.Reader, blob.Length)).
+					WithJob(jobID).
+					WithOffset(blob.Offset)
+				_, err := client.PutObject(putObjRequest)
+				return err
+			})
+			rec.record(OpBlobPut, start, 1, blob.Length, *blob.Name, err)
+			if err != nil {
+				return fmt.Errorf("job %s: putting %s at offset %d: %w", jobID, *blob.Name, blob.Offset, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return phases, err
+		}
+	}
+	return phases, nil
 }
@@ -0,0 +1,137 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/SpectraLogic/ds3_go_sdk/ds3"
+	"github.com/joshcarter/warp-ds3/pkg/generator"
+	"github.com/minio/pkg/console"
+)
+
+// BulkVerify benchmarks DS3 verify bulk jobs.
+type BulkVerify struct {
+	Common
+	BulkNum       int
+	CreateObjects int
+	objects       generator.Objects
+}
+
+// Prepare will create an empty bucket or delete any content already there
+// and upload a number of objects using bulk put jobs.
+// It waits for the jobs to complete, so verify jobs read the objects
+// from where the data policy persisted them instead of from cache.
+func (g *BulkVerify) Prepare(ctx context.Context) error {
+	if err := g.createEmptyBucket(ctx); err != nil {
+		return err
+	}
+	var jobs []string
+	var err error
+	g.objects, jobs, err = g.uploadBulkObjects(ctx, g.CreateObjects, g.BulkNum)
+	if err != nil {
+		return err
+	}
+	console.Eraseline()
+	console.Info("\rWaiting for ", len(jobs), " upload jobs to complete")
+	client, done := g.Client()
+	defer done()
+	for _, job := range jobs {
+		if _, err := g.waitForJob(ctx, client, job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+func (g *BulkVerify) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var wg sync.WaitGroup
+	wg.Add(g.Concurrency)
+	c := g.newCollector()
+	if g.AutoTermDur > 0 {
+		ctx = c.AutoTerm(ctx, "BULKVERIFY", g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
+	}
+	bulkNum := g.BulkNum
+	if bulkNum > len(g.objects) {
+		bulkNum = len(g.objects)
+	}
+
+	for i := 0; i < g.Concurrency; i++ {
+		go func(i int) {
+			rng := rand.New(rand.NewSource(int64(i)))
+			rcv := c.Receiver()
+			defer wg.Done()
+			done := ctx.Done()
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				names := make([]string, bulkNum)
+				totalSize := int64(0)
+				for j, idx := range rng.Perm(len(g.objects))[:bulkNum] {
+					names[j] = g.objects[idx].Name
+					totalSize += g.objects[idx].Size
+				}
+
+				op := Operation{
+					OpType:   "BULKVERIFY",
+					Thread:   uint16(i),
+					Size:     totalSize,
+					File:     names[0],
+					ObjPerOp: bulkNum,
+				}
+				client, cldone := g.Client()
+				op.Endpoint = g.endpoint(client)
+				op.Start = time.Now()
+				err := g.verifyBulkObjects(ctx, client, names, &op)
+				op.End = time.Now()
+				cldone()
+				if ctx.Err() != nil {
+					// The benchmark ended while the job was in progress.
+					return
+				}
+				if err != nil {
+					g.Error("bulk verify error: ", err)
+					op.Err = err.Error()
+				}
+				rcv <- op
+			}
+		}(i)
+	}
+	wg.Wait()
+	return c.Close(), nil
+}
+
+// verifyBulkObjects verifies the named objects through a single DS3 verify job
+// and waits for the server to complete it.
+// Since completion is detected by polling, the job may have completed
+// up to BulkOpts.JobPollInterval before this returns.
+func (g *BulkVerify) verifyBulkObjects(ctx context.Context, client *ds3.Client, names []string, op *Operation) (err error) {
+	resp, err := client.VerifyBulkJobSpectraS3(g.BulkOpts.verifyBulkRequest(g.Bucket, names))
+	if err != nil {
+		return fmt.Errorf("creating job: %w", err)
+	}
+	jobID := resp.MasterObjectList.JobId
+	op.JobID = jobID
+	defer func() {
+		if err != nil {
+			g.failJob(client, jobID)
+		}
+	}()
+	_, err = g.waitForJob(ctx, client, jobID)
+	return err
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+func (g *BulkVerify) Cleanup(ctx context.Context) {
+	g.deleteObjects(ctx, objectNames(g.objects))
+}
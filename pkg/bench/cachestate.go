@@ -0,0 +1,156 @@
+package bench
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/SpectraLogic/ds3_go_sdk/ds3"
+	"github.com/SpectraLogic/ds3_go_sdk/ds3/models"
+)
+
+// CacheSample is the combined state of all cache filesystems at one point in time.
+type CacheSample struct {
+	Time        time.Time `json:"time"`
+	Total       int64     `json:"total_bytes"`
+	Used        int64     `json:"used_bytes"`
+	Available   int64     `json:"available_bytes"`
+	JobLocked   int64     `json:"job_locked_bytes"`
+	Unavailable int64     `json:"unavailable_bytes"`
+	Err         string    `json:"err,omitempty"`
+}
+
+// CacheSamples is a time series of cache states.
+type CacheSamples []CacheSample
+
+// UsedPct returns the used capacity in percent of the total capacity.
+func (s CacheSample) UsedPct() float64 {
+	if s.Total <= 0 {
+		return 0
+	}
+	return 100 * float64(s.Used) / float64(s.Total)
+}
+
+// String returns a human readable representation of the sample.
+func (s CacheSample) String() string {
+	if s.Err != "" {
+		return "error: " + s.Err
+	}
+	locked := 0.0
+	if s.Total > 0 {
+		locked = 100 * float64(s.JobLocked) / float64(s.Total)
+	}
+	return fmt.Sprintf("%.1f%% used, %.1f%% job locked", s.UsedPct(), locked)
+}
+
+// SampleCache polls the cache state every interval until ctx is canceled
+// and returns the samples.
+// Failed polls are recorded as samples with an error.
+// If sampled is not nil it is called with every sample as it is taken.
+func SampleCache(ctx context.Context, client func() (*ds3.Client, func()), interval time.Duration, sampled func(CacheSample)) CacheSamples {
+	return pollSamples(ctx, interval, func() CacheSample {
+		cl, done := client()
+		defer done()
+		return sampleCache(cl)
+	}, sampled)
+}
+
+// sampleCache returns the current cache state.
+func sampleCache(client *ds3.Client) CacheSample {
+	s := CacheSample{Time: time.Now()}
+	resp, err := client.GetCacheStateSpectraS3(models.NewGetCacheStateSpectraS3Request())
+	if err != nil {
+		s.Err = err.Error()
+		return s
+	}
+	for _, fs := range resp.CacheInformation.Filesystems {
+		s.Total += fs.TotalCapacityInBytes
+		s.Used += fs.UsedCapacityInBytes
+		s.Available += fs.AvailableCapacityInBytes
+		s.JobLocked += fs.JobLockedCacheInBytes
+		s.Unavailable += fs.UnavailableCapacityInBytes
+	}
+	return s
+}
+
+// At returns the last successful sample taken at or before t.
+func (c CacheSamples) At(t time.Time) (CacheSample, bool) {
+	idx := sort.Search(len(c), func(i int) bool {
+		return c[i].Time.After(t)
+	})
+	for i := idx - 1; i >= 0; i-- {
+		if c[i].Err == "" {
+			return c[i], true
+		}
+	}
+	return CacheSample{}, false
+}
+
+// CSV will write the samples to w as CSV.
+func (c CacheSamples) CSV(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	_, err := bw.WriteString("time\ttotal_bytes\tused_bytes\tavailable_bytes\tjob_locked_bytes\tunavailable_bytes\terror\n")
+	if err != nil {
+		return err
+	}
+	for _, s := range c {
+		_, err := fmt.Fprintf(bw, "%s\t%d\t%d\t%d\t%d\t%d\t%s\n", s.Time.Format(time.RFC3339Nano), s.Total, s.Used, s.Available, s.JobLocked, s.Unavailable, csvEscapeString(s.Err))
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// CacheSamplesFromCSV reads cache samples written by CacheSamples.CSV.
+func CacheSamplesFromCSV(r io.Reader) (CacheSamples, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = '\t'
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	fieldIdx := make(map[string]int, len(header))
+	for i, s := range header {
+		fieldIdx[s] = i
+	}
+	for _, f := range []string{"time", "total_bytes", "used_bytes", "available_bytes", "job_locked_bytes", "unavailable_bytes", "error"} {
+		if _, ok := fieldIdx[f]; !ok {
+			return nil, fmt.Errorf("missing field %q", f)
+		}
+	}
+	var res CacheSamples
+	for {
+		values, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		t, err := time.Parse(time.RFC3339Nano, values[fieldIdx["time"]])
+		if err != nil {
+			return nil, err
+		}
+		s := CacheSample{Time: t, Err: values[fieldIdx["error"]]}
+		for name, dst := range map[string]*int64{
+			"total_bytes":       &s.Total,
+			"used_bytes":        &s.Used,
+			"available_bytes":   &s.Available,
+			"job_locked_bytes":  &s.JobLocked,
+			"unavailable_bytes": &s.Unavailable,
+		} {
+			if *dst, err = strconv.ParseInt(values[fieldIdx[name]], 10, 64); err != nil {
+				return nil, err
+			}
+		}
+		res = append(res, s)
+	}
+}
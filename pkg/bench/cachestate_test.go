@@ -0,0 +1,39 @@
+package bench
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCacheSamples(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := CacheSamples{
+		{Time: start, Total: 100, Used: 10, Available: 90},
+		{Time: start.Add(time.Second), Err: "connection refused"},
+		{Time: start.Add(2 * time.Second), Total: 100, Used: 50, Available: 40, JobLocked: 10},
+	}
+	var buf bytes.Buffer
+	if err := samples.CSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := CacheSamplesFromCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, samples) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, samples)
+	}
+
+	if _, ok := samples.At(start.Add(-time.Second)); ok {
+		t.Error("got sample before first sample")
+	}
+	// Failed samples are skipped.
+	if s, ok := samples.At(start.Add(1500 * time.Millisecond)); !ok || s.Used != 10 {
+		t.Errorf("want first sample, got %+v", s)
+	}
+	if s, ok := samples.At(start.Add(time.Hour)); !ok || s.UsedPct() != 50 {
+		t.Errorf("want last sample, got %+v", s)
+	}
+}
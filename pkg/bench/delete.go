@@ -0,0 +1,127 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/SpectraLogic/ds3_go_sdk/ds3"
+	"github.com/SpectraLogic/ds3_go_sdk/ds3/models"
+)
+
+// Delete benchmarks delete speed.
+type Delete struct {
+	Common
+	CreateObjects int
+	// BatchSize is the number of objects removed per operation.
+	// A batch size of 1 uses DeleteObject, larger batches use DeleteObjects.
+	BatchSize int
+	batches   chan []string
+	failed    createdObjects
+}
+
+// Prepare will create an empty bucket or delete any content already there
+// and upload a number of objects.
+func (d *Delete) Prepare(ctx context.Context) error {
+	if err := d.createEmptyBucket(ctx); err != nil {
+		return err
+	}
+	objs, err := d.uploadObjects(ctx, d.CreateObjects)
+	if err != nil {
+		return err
+	}
+	names := objectNames(objs)
+	d.batches = make(chan []string, len(names)/d.BatchSize+1)
+	for len(names) > 0 {
+		n := d.BatchSize
+		if n > len(names) {
+			n = len(names)
+		}
+		d.batches <- names[:n]
+		names = names[n:]
+	}
+	close(d.batches)
+	return nil
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+// Workers stop when all prepared objects have been deleted.
+func (d *Delete) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var wg sync.WaitGroup
+	wg.Add(d.Concurrency)
+	c := d.newCollector()
+	if d.AutoTermDur > 0 {
+		ctx = c.AutoTerm(ctx, http.MethodDelete, d.AutoTermScale, autoTermCheck, autoTermSamples, d.AutoTermDur)
+	}
+
+	for i := 0; i < d.Concurrency; i++ {
+		go func(i int) {
+			rcv := c.Receiver()
+			defer wg.Done()
+			done := ctx.Done()
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				batch, ok := <-d.batches
+				if !ok {
+					// All prepared objects are gone.
+					return
+				}
+				op := Operation{
+					OpType:   http.MethodDelete,
+					Thread:   uint16(i),
+					Size:     0,
+					File:     batch[0],
+					ObjPerOp: len(batch),
+				}
+				client, cldone := d.Client()
+				op.Endpoint = d.endpoint(client)
+				op.Start = time.Now()
+				err := d.deleteBatch(client, batch)
+				op.End = time.Now()
+				cldone()
+				if err != nil {
+					d.Error("delete error: ", err)
+					op.Err = err.Error()
+					d.failed.add(batch...)
+				}
+				rcv <- op
+			}
+		}(i)
+	}
+	wg.Wait()
+	return c.Close(), nil
+}
+
+// deleteBatch removes the named objects.
+func (d *Delete) deleteBatch(client *ds3.Client, names []string) error {
+	if d.BatchSize == 1 {
+		return deleteObject(client, d.Bucket, names[0])
+	}
+	resp, err := client.DeleteObjects(models.NewDeleteObjectsRequest(d.Bucket, names))
+	if err != nil {
+		return err
+	}
+	if errs := resp.DeleteResult.Errors; len(errs) > 0 {
+		return fmt.Errorf("%d of %d objects not deleted, first: %s: %s",
+			len(errs), len(names), stringOrEmpty(errs[0].Key), stringOrEmpty(errs[0].Message))
+	}
+	return nil
+}
+
+// Cleanup deletes objects left in the bucket.
+func (d *Delete) Cleanup(ctx context.Context) {
+	names := append([]string(nil), d.failed.get()...)
+	for batch := range d.batches {
+		names = append(names, batch...)
+	}
+	d.deleteObjects(ctx, names)
+}
@@ -0,0 +1,231 @@
+package bench
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/SpectraLogic/ds3_go_sdk/ds3"
+	"github.com/SpectraLogic/ds3_go_sdk/ds3/models"
+	"github.com/dustin/go-humanize"
+)
+
+// JobSample is a summary of the active jobs on the server at one point in time.
+type JobSample struct {
+	Time time.Time `json:"time"`
+	// Number of active jobs by type.
+	Active int `json:"active"`
+	Put    int `json:"put"`
+	Get    int `json:"get"`
+	Verify int `json:"verify"`
+	// Total size of the active jobs, the part of it in cache
+	// and the part that has been completed.
+	Bytes          int64 `json:"bytes"`
+	CachedBytes    int64 `json:"cached_bytes"`
+	CompletedBytes int64 `json:"completed_bytes"`
+	// Number of chunks not yet completed and the part of them entirely
+	// in cache, of the first ChunkJobs active jobs.
+	ChunkJobs    int    `json:"chunk_jobs"`
+	Chunks       int    `json:"chunks"`
+	CachedChunks int    `json:"cached_chunks"`
+	Err          string `json:"err,omitempty"`
+}
+
+// JobSamples is a time series of active job summaries.
+type JobSamples []JobSample
+
+// QueuedBytes returns the size of the active jobs not yet completed.
+func (s JobSample) QueuedBytes() int64 {
+	return s.Bytes - s.CompletedBytes
+}
+
+// String returns a human readable representation of the sample.
+func (s JobSample) String() string {
+	if s.Err != "" {
+		return "error: " + s.Err
+	}
+	queued := s.QueuedBytes()
+	if queued < 0 {
+		queued = 0
+	}
+	res := fmt.Sprintf("%d active jobs, %s queued", s.Active, humanize.IBytes(uint64(queued)))
+	if s.ChunkJobs > 0 {
+		res += fmt.Sprintf(", %d chunks (%d in cache) in %d jobs", s.Chunks, s.CachedChunks, s.ChunkJobs)
+	}
+	return res
+}
+
+// At returns the last successful sample taken at or before t.
+func (j JobSamples) At(t time.Time) (JobSample, bool) {
+	idx := sort.Search(len(j), func(i int) bool {
+		return j[i].Time.After(t)
+	})
+	for i := idx - 1; i >= 0; i-- {
+		if j[i].Err == "" {
+			return j[i], true
+		}
+	}
+	return JobSample{}, false
+}
+
+// SampleJobs polls the active jobs every interval until ctx is canceled
+// and returns the samples.
+// Failed polls are recorded as samples with an error.
+// If sampled is not nil it is called with every sample as it is taken.
+// The chunks of up to chunkJobs active jobs are counted per sample,
+// which takes one request per job.
+func SampleJobs(ctx context.Context, client func() (*ds3.Client, func()), interval time.Duration, chunkJobs int, sampled func(JobSample)) JobSamples {
+	return pollSamples(ctx, interval, func() JobSample {
+		cl, done := client()
+		defer done()
+		return sampleJobs(cl, chunkJobs)
+	}, sampled)
+}
+
+// sampleJobs returns a summary of the currently active jobs.
+// The active job listing has no chunk information, so the chunks
+// of the first chunkJobs jobs are listed to count them.
+func sampleJobs(client *ds3.Client, chunkJobs int) JobSample {
+	const pageLength = 1000
+	s := JobSample{Time: time.Now()}
+	for page := 0; ; page++ {
+		req := models.NewGetActiveJobsSpectraS3Request().
+			WithPageLength(pageLength).
+			WithPageOffset(page * pageLength)
+		resp, err := client.GetActiveJobsSpectraS3(req)
+		if err != nil {
+			s.Err = err.Error()
+			return s
+		}
+		jobs := resp.ActiveJobList.ActiveJobs
+		for _, job := range jobs {
+			s.Active++
+			switch job.RequestType {
+			case models.JOB_REQUEST_TYPE_PUT:
+				s.Put++
+			case models.JOB_REQUEST_TYPE_GET:
+				s.Get++
+			case models.JOB_REQUEST_TYPE_VERIFY:
+				s.Verify++
+			}
+			s.Bytes += job.OriginalSizeInBytes
+			s.CachedBytes += job.CachedSizeInBytes
+			s.CompletedBytes += job.CompletedSizeInBytes
+			if s.ChunkJobs < chunkJobs {
+				if err := countChunks(client, job.Id, &s); err != nil {
+					s.Err = err.Error()
+					return s
+				}
+			}
+		}
+		if len(jobs) < pageLength {
+			return s
+		}
+	}
+}
+
+// countChunks adds the chunks of the job to s.
+func countChunks(client *ds3.Client, jobID string, s *JobSample) error {
+	resp, err := client.GetJobSpectraS3(models.NewGetJobSpectraS3Request(jobID))
+	if err != nil {
+		return fmt.Errorf("job %s: listing chunks: %w", jobID, err)
+	}
+	for _, chunk := range resp.MasterObjectList.Objects {
+		s.Chunks++
+		if chunkInCache(chunk) {
+			s.CachedChunks++
+		}
+	}
+	s.ChunkJobs++
+	return nil
+}
+
+// chunkInCache returns whether all blobs of chunk are in cache.
+func chunkInCache(chunk models.Objects) bool {
+	for _, blob := range chunk.Objects {
+		if blob.InCache == nil || !*blob.InCache {
+			return false
+		}
+	}
+	return true
+}
+
+// CSV will write the samples to w as CSV.
+func (j JobSamples) CSV(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	_, err := bw.WriteString("time\tactive\tput\tget\tverify\tbytes\tcached_bytes\tcompleted_bytes\tchunk_jobs\tchunks\tcached_chunks\terror\n")
+	if err != nil {
+		return err
+	}
+	for _, s := range j {
+		_, err := fmt.Fprintf(bw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%s\n", s.Time.Format(time.RFC3339Nano), s.Active, s.Put, s.Get, s.Verify, s.Bytes, s.CachedBytes, s.CompletedBytes, s.ChunkJobs, s.Chunks, s.CachedChunks, csvEscapeString(s.Err))
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// JobSamplesFromCSV reads job samples written by JobSamples.CSV.
+func JobSamplesFromCSV(r io.Reader) (JobSamples, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = '\t'
+	cr.ReuseRecord = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	fieldIdx := make(map[string]int, len(header))
+	for i, s := range header {
+		fieldIdx[s] = i
+	}
+	for _, f := range []string{"time", "active", "put", "get", "verify", "bytes", "cached_bytes", "completed_bytes", "chunk_jobs", "chunks", "cached_chunks", "error"} {
+		if _, ok := fieldIdx[f]; !ok {
+			return nil, fmt.Errorf("missing field %q", f)
+		}
+	}
+	var res JobSamples
+	for {
+		values, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		t, err := time.Parse(time.RFC3339Nano, values[fieldIdx["time"]])
+		if err != nil {
+			return nil, err
+		}
+		s := JobSample{Time: t, Err: values[fieldIdx["error"]]}
+		for name, dst := range map[string]*int{
+			"active":        &s.Active,
+			"put":           &s.Put,
+			"get":           &s.Get,
+			"verify":        &s.Verify,
+			"chunk_jobs":    &s.ChunkJobs,
+			"chunks":        &s.Chunks,
+			"cached_chunks": &s.CachedChunks,
+		} {
+			if *dst, err = strconv.Atoi(values[fieldIdx[name]]); err != nil {
+				return nil, err
+			}
+		}
+		for name, dst := range map[string]*int64{
+			"bytes":           &s.Bytes,
+			"cached_bytes":    &s.CachedBytes,
+			"completed_bytes": &s.CompletedBytes,
+		} {
+			if *dst, err = strconv.ParseInt(values[fieldIdx[name]], 10, 64); err != nil {
+				return nil, err
+			}
+		}
+		res = append(res, s)
+	}
+}
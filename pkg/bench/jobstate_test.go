@@ -0,0 +1,36 @@
+package bench
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJobSamplesCSV(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := JobSamples{
+		{Time: start, Active: 3, Put: 2, Verify: 1, Bytes: 300, CachedBytes: 200, CompletedBytes: 100, ChunkJobs: 2, Chunks: 4, CachedChunks: 1},
+		{Time: start.Add(time.Second), Err: "connection refused"},
+	}
+	var buf bytes.Buffer
+	if err := samples.CSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := JobSamplesFromCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, samples) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, samples)
+	}
+	if q := got[0].QueuedBytes(); q != 200 {
+		t.Errorf("want 200 queued bytes, got %d", q)
+	}
+	if s, ok := got.At(start.Add(time.Hour)); !ok || s.Chunks != 4 {
+		t.Errorf("want first sample, got %+v", s)
+	}
+	if want := "3 active jobs, 200 B queued, 4 chunks (1 in cache) in 2 jobs"; got[0].String() != want {
+		t.Errorf("want %q, got %q", want, got[0].String())
+	}
+}
@@ -0,0 +1,223 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SpectraLogic/ds3_go_sdk/ds3"
+	"github.com/SpectraLogic/ds3_go_sdk/ds3/models"
+	"github.com/joshcarter/warp-ds3/pkg/generator"
+)
+
+// List benchmarks complete paginated bucket listings.
+type List struct {
+	Common
+	CreateObjects int
+	// MaxKeys is the number of keys requested per page.
+	MaxKeys int
+	// FullDetails lists with GetObjectsWithFullDetailsSpectraS3
+	// instead of GetBucket.
+	FullDetails bool
+	objects     generator.Objects
+	prefixes    []string
+	counts      map[string]int
+	names       map[string]struct{}
+}
+
+// Prepare will create an empty bucket or delete any content already there
+// and upload a number of objects.
+// Every upload thread uses its own prefix.
+func (d *List) Prepare(ctx context.Context) error {
+	if err := d.createEmptyBucket(ctx); err != nil {
+		return err
+	}
+	var err error
+	d.objects, err = d.uploadObjects(ctx, d.CreateObjects)
+	if err != nil {
+		return err
+	}
+	d.prefixes = d.objects.Prefixes()
+	d.counts = make(map[string]int, len(d.prefixes))
+	d.names = make(map[string]struct{}, len(d.objects))
+	for _, obj := range d.objects {
+		d.counts[obj.Prefix]++
+		d.names[obj.Name] = struct{}{}
+	}
+	return nil
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+// Every listing must contain the objects uploaded by Prepare under its prefix.
+// Other objects in the bucket are not checked.
+func (d *List) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var wg sync.WaitGroup
+	wg.Add(d.Concurrency)
+	c := d.newCollector()
+	opType := "LIST"
+	if d.FullDetails {
+		opType = "LIST_DETAILS"
+	}
+	if d.AutoTermDur > 0 {
+		ctx = c.AutoTerm(ctx, opType, d.AutoTermScale, autoTermCheck, autoTermSamples, d.AutoTermDur)
+	}
+
+	for i := 0; i < d.Concurrency; i++ {
+		go func(i int) {
+			rcv := c.Receiver()
+			defer wg.Done()
+			done := ctx.Done()
+			prefix := d.prefixes[i%len(d.prefixes)]
+			want := d.counts[prefix]
+			if prefix == "" {
+				want = len(d.objects)
+			}
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				client, cldone := d.Client()
+				var ops Operations
+				var found int
+				var err error
+				if d.FullDetails {
+					ops, found, err = d.listDetails(ctx, client, prefix, opType, i)
+				} else {
+					ops, found, err = d.listBucket(ctx, client, prefix, opType, i)
+				}
+				cldone()
+				if ctx.Err() != nil {
+					// The benchmark ended while listing.
+					return
+				}
+				if err != nil {
+					d.Error("list error: ", err)
+				} else if found != want {
+					err = fmt.Errorf("unexpected number of uploaded objects listed under %q. want: %d, got: %d", prefix, want, found)
+					d.Error(err)
+					ops[len(ops)-1].Err = err.Error()
+				}
+				for _, op := range ops {
+					rcv <- op
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	return c.Close(), nil
+}
+
+// listBucket lists every object under prefix using GetBucket.
+// One operation is returned per page. If a page fails, the last operation
+// holds the error.
+// found is the number of listed objects uploaded by Prepare.
+func (d *List) listBucket(ctx context.Context, client *ds3.Client, prefix, opType string, thread int) (ops Operations, found int, err error) {
+	marker := ""
+	for ctx.Err() == nil {
+		req := models.NewGetBucketRequest(d.Bucket).WithMaxKeys(d.MaxKeys)
+		if prefix != "" {
+			req = req.WithPrefix(prefix + "/")
+		}
+		if marker != "" {
+			req = req.WithMarker(marker)
+		}
+		op := Operation{
+			OpType:   opType,
+			Thread:   uint16(thread),
+			File:     prefix,
+			Endpoint: d.endpoint(client),
+		}
+		op.Start = time.Now()
+		resp, err := client.GetBucket(req)
+		op.End = time.Now()
+		if err != nil {
+			op.Err = err.Error()
+			return append(ops, op), found, err
+		}
+		result := resp.ListBucketResult
+		op.ObjPerOp = len(result.Objects)
+		for _, obj := range result.Objects {
+			if obj.Key != nil && d.uploaded(*obj.Key) {
+				found++
+			}
+		}
+		ops = append(ops, op)
+		if !result.Truncated {
+			break
+		}
+		switch {
+		case result.NextMarker != nil:
+			marker = *result.NextMarker
+		case len(result.Objects) > 0 && result.Objects[len(result.Objects)-1].Key != nil:
+			marker = *result.Objects[len(result.Objects)-1].Key
+		default:
+			err := fmt.Errorf("truncated listing of %q without a marker", prefix)
+			ops[len(ops)-1].Err = err.Error()
+			return ops, found, err
+		}
+	}
+	return ops, found, nil
+}
+
+// listDetails lists every object under prefix using GetObjectsWithFullDetailsSpectraS3.
+// One operation is returned per page. If a page fails, the last operation
+// holds the error.
+// found is the number of listed objects uploaded by Prepare.
+func (d *List) listDetails(ctx context.Context, client *ds3.Client, prefix, opType string, thread int) (ops Operations, found int, err error) {
+	marker := ""
+	for ctx.Err() == nil {
+		req := models.NewGetObjectsWithFullDetailsSpectraS3Request().
+			WithBucketId(d.Bucket).
+			WithPageLength(d.MaxKeys)
+		if prefix != "" {
+			// Name filters are matched with SQL LIKE semantics.
+			req = req.WithName(prefix + "/%")
+		}
+		if marker != "" {
+			req = req.WithPageStartMarker(marker)
+		}
+		op := Operation{
+			OpType:   opType,
+			Thread:   uint16(thread),
+			File:     prefix,
+			Endpoint: d.endpoint(client),
+		}
+		op.Start = time.Now()
+		resp, err := client.GetObjectsWithFullDetailsSpectraS3(req)
+		op.End = time.Now()
+		if err != nil {
+			op.Err = err.Error()
+			return append(ops, op), found, err
+		}
+		objs := resp.DetailedS3ObjectList.DetailedS3Objects
+		op.ObjPerOp = len(objs)
+		for _, obj := range objs {
+			if obj.Name != nil && d.uploaded(*obj.Name) {
+				found++
+			}
+		}
+		ops = append(ops, op)
+		if len(objs) < d.MaxKeys {
+			break
+		}
+		marker = objs[len(objs)-1].Id
+	}
+	return ops, found, nil
+}
+
+// uploaded returns whether the named object was uploaded by Prepare.
+func (d *List) uploaded(name string) bool {
+	_, ok := d.names[name]
+	return ok
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+func (d *List) Cleanup(ctx context.Context) {
+	d.deleteObjects(ctx, objectNames(d.objects))
+}
@@ -0,0 +1,208 @@
+package bench
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// OpsSummary summarizes the operations of one type that ended between From and To.
+type OpsSummary struct {
+	OpType  string    `json:"op"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Ops     int       `json:"ops"`
+	Objects int       `json:"objects"`
+	Bytes   int64     `json:"bytes"`
+	Errors  int       `json:"errors"`
+}
+
+// OpsSummaries is a series of operation summaries, possibly from several clients.
+type OpsSummaries []OpsSummary
+
+// LiveBatch is a numbered batch of summaries returned by LiveOps.Take.
+type LiveBatch struct {
+	Seq uint64       `json:"seq"`
+	Ops OpsSummaries `json:"ops"`
+}
+
+// LiveOps summarizes operations as they are recorded,
+// so progress can be reported while the benchmark runs.
+type LiveOps struct {
+	mu   sync.Mutex
+	from time.Time
+	ops  map[string]*OpsSummary
+	// Batches taken but not acknowledged.
+	pending []LiveBatch
+	seq     uint64
+}
+
+// Add an operation.
+func (l *LiveOps) Add(op Operation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.from.IsZero() {
+		// The first summary starts with the first operation.
+		l.from = op.Start
+	}
+	if l.ops == nil {
+		l.ops = make(map[string]*OpsSummary)
+	}
+	s := l.ops[op.OpType]
+	if s == nil {
+		s = &OpsSummary{OpType: op.OpType}
+		l.ops[op.OpType] = s
+	}
+	s.Ops++
+	s.Objects += op.ObjPerOp
+	s.Bytes += op.Size
+	if op.Err != "" {
+		s.Errors++
+	}
+}
+
+// Take drops the batches up to and including ack and returns the remaining ones,
+// ending with a batch of the operations added since the last call.
+// Summaries in a batch are sorted by type.
+// Batches are returned until acknowledged, so they can be resent if a reply is lost.
+func (l *LiveOps) Take(ack uint64) []LiveBatch {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for len(l.pending) > 0 && l.pending[0].Seq <= ack {
+		l.pending = l.pending[1:]
+	}
+	if !l.from.IsZero() && len(l.ops) > 0 {
+		now := time.Now()
+		res := make(OpsSummaries, 0, len(l.ops))
+		for _, s := range l.ops {
+			s.From, s.To = l.from, now
+			res = append(res, *s)
+		}
+		sort.Slice(res, func(i, j int) bool {
+			return res[i].OpType < res[j].OpType
+		})
+		l.seq++
+		l.pending = append(l.pending, LiveBatch{Seq: l.seq, Ops: res})
+		l.from = now
+		l.ops = nil
+	}
+	if len(l.pending) == 0 {
+		return nil
+	}
+	return append([]LiveBatch(nil), l.pending...)
+}
+
+// OpTypes returns the operation types in the summaries, sorted.
+func (s OpsSummaries) OpTypes() []string {
+	found := make(map[string]struct{})
+	var res []string
+	for _, sum := range s {
+		if _, ok := found[sum.OpType]; ok {
+			continue
+		}
+		found[sum.OpType] = struct{}{}
+		res = append(res, sum.OpType)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Start returns the start of the earliest summary.
+func (s OpsSummaries) Start() time.Time {
+	var start time.Time
+	for _, sum := range s {
+		if start.IsZero() || sum.From.Before(start) {
+			start = sum.From
+		}
+	}
+	return start
+}
+
+// End returns the end of the latest summary.
+func (s OpsSummaries) End() time.Time {
+	var end time.Time
+	for _, sum := range s {
+		if sum.To.After(end) {
+			end = sum.To
+		}
+	}
+	return end
+}
+
+// Rate returns the combined bytes and objects per second of operations of
+// type opType between from and to.
+// Summaries partially inside the window are counted proportionally.
+func (s OpsSummaries) Rate(opType string, from, to time.Time) (bps, objs float64) {
+	window := to.Sub(from)
+	if window <= 0 {
+		return 0, 0
+	}
+	var bytes, objects float64
+	for _, sum := range s {
+		if sum.OpType != opType || !sum.To.After(from) || !sum.From.Before(to) {
+			continue
+		}
+		dur := sum.To.Sub(sum.From)
+		share := 1.0
+		if dur > 0 {
+			start, end := sum.From, sum.To
+			if start.Before(from) {
+				start = from
+			}
+			if end.After(to) {
+				end = to
+			}
+			share = float64(end.Sub(start)) / float64(dur)
+		}
+		bytes += share * float64(sum.Bytes)
+		objects += share * float64(sum.Objects)
+	}
+	secs := window.Seconds()
+	return bytes / secs, objects / secs
+}
+
+// Dominant returns the operation type with the most operations,
+// not counting bulk job phases.
+func (s OpsSummaries) Dominant() string {
+	counts := make(map[string]int)
+	for _, sum := range s {
+		if !(Operation{OpType: sum.OpType}).IsJobPhase() {
+			counts[sum.OpType] += sum.Ops
+		}
+	}
+	best, bestN := "", -1
+	for typ, n := range counts {
+		if n > bestN || (n == bestN && typ < best) {
+			best, bestN = typ, n
+		}
+	}
+	return best
+}
+
+// AutoTerm returns whether the throughput of opType until 'to' is stable
+// by the criteria of Collector.AutoTerm.
+// If it is, a description of the throughput is returned.
+func (s OpsSummaries) AutoTerm(opType string, to time.Time, threshold float64, minDur time.Duration) (stable bool, status string) {
+	var start time.Time
+	for _, sum := range s {
+		if sum.OpType == opType && (start.IsZero() || sum.From.Before(start)) {
+			start = sum.From
+		}
+	}
+	if start.IsZero() || to.Sub(start) <= minDur*autoTermSamples/autoTermCheck {
+		// We don't have enough.
+		return false, ""
+	}
+	segDur := to.Sub(start) / autoTermSamples
+	mbs := make([]float64, autoTermSamples)
+	objs := make([]float64, autoTermSamples)
+	for i := range mbs {
+		from := start.Add(segDur * time.Duration(i))
+		bps, o := s.Rate(opType, from, from.Add(segDur))
+		mbs[i], objs[i] = bps/(1<<20), o
+	}
+	if !stableSpeed(mbs, objs, threshold, autoTermCheck) {
+		return false, ""
+	}
+	return true, autoTermStatus(mbs[len(mbs)-1], objs[len(objs)-1], threshold, segDur*autoTermCheck)
+}
@@ -0,0 +1,107 @@
+package bench
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLiveOps(t *testing.T) {
+	var l LiveOps
+	if got := l.Take(0); got != nil {
+		t.Fatalf("want no summaries, got %v", got)
+	}
+	start := time.Now().Add(-time.Second)
+	l.Add(Operation{OpType: "BULKPUT", ObjPerOp: 10, Size: 1000, Start: start, End: start.Add(time.Millisecond)})
+	l.Add(Operation{OpType: "BULKPUT", ObjPerOp: 10, Size: 1000, Err: "failed", Start: start, End: start.Add(time.Millisecond)})
+	l.Add(Operation{OpType: "BLOB_PUT", ObjPerOp: 1, Size: 100, Start: start, End: start.Add(time.Millisecond)})
+	batches := l.Take(0)
+	if len(batches) != 1 || batches[0].Seq != 1 {
+		t.Fatalf("want batch 1, got %+v", batches)
+	}
+	got := batches[0].Ops
+	if len(got) != 2 {
+		t.Fatalf("want 2 summaries, got %d", len(got))
+	}
+	want := OpsSummary{OpType: "BULKPUT", From: start, To: got[1].To, Ops: 2, Objects: 20, Bytes: 2000, Errors: 1}
+	if got[0].OpType != "BLOB_PUT" || got[1] != want {
+		t.Fatalf("unexpected summaries: %+v", got)
+	}
+
+	// Batch 1 was not acknowledged, so it is sent again.
+	l.Add(Operation{OpType: "BULKPUT", Start: start})
+	batches = l.Take(0)
+	if len(batches) != 2 || batches[0].Seq != 1 || batches[1].Seq != 2 {
+		t.Fatalf("want batches 1 and 2, got %+v", batches)
+	}
+	if from := batches[1].Ops[0].From; !from.Equal(want.To) {
+		t.Errorf("want summary to start at %v, got %v", want.To, from)
+	}
+	if batches = l.Take(2); batches != nil {
+		t.Errorf("want acknowledged batches dropped, got %+v", batches)
+	}
+}
+
+func TestOpsSummariesRate(t *testing.T) {
+	at := func(s int) time.Time {
+		return time.Unix(1700000000+int64(s), 0)
+	}
+	// Two clients reporting every 2 seconds.
+	s := OpsSummaries{
+		{OpType: "BULKGET", From: at(0), To: at(2), Bytes: 2000, Objects: 20},
+		{OpType: "BULKGET", From: at(2), To: at(4), Bytes: 2000, Objects: 20},
+		{OpType: "BULKGET", From: at(1), To: at(3), Bytes: 4000, Objects: 40},
+		{OpType: "HEAD", From: at(1), To: at(3), Objects: 10},
+	}
+	if got := s.OpTypes(); len(got) != 2 || got[0] != "BULKGET" || got[1] != "HEAD" {
+		t.Errorf("unexpected op types: %v", got)
+	}
+	if !s.Start().Equal(at(0)) || !s.End().Equal(at(4)) {
+		t.Errorf("unexpected range: %v - %v", s.Start(), s.End())
+	}
+	bps, objs := s.Rate("BULKGET", at(2), at(3))
+	// 1000 from each of the first client's summaries and 2000 from the second client.
+	if math.Abs(bps-3000) > 1e-9 || math.Abs(objs-30) > 1e-9 {
+		t.Errorf("want 3000 B/s, 30 obj/s, got %v B/s, %v obj/s", bps, objs)
+	}
+	if bps, objs = s.Rate("BULKGET", at(10), at(12)); bps != 0 || objs != 0 {
+		t.Errorf("want no throughput outside summaries, got %v B/s, %v obj/s", bps, objs)
+	}
+}
+
+func TestOpsSummariesAutoTerm(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	// Two clients reporting every second, ramping up for 10 seconds.
+	var s OpsSummaries
+	for i := 0; i < 60; i++ {
+		bytes := int64(10 << 20)
+		if i < 10 {
+			bytes = int64(i) << 20
+		}
+		for _, offset := range []time.Duration{0, 300 * time.Millisecond} {
+			from := start.Add(time.Duration(i)*time.Second + offset)
+			s = append(s,
+				OpsSummary{OpType: "BULKPUT", From: from, To: from.Add(time.Second), Ops: 1, Objects: 10, Bytes: bytes},
+				OpsSummary{OpType: OpBlobPut, From: from, To: from.Add(time.Second), Ops: 10, Objects: 10, Bytes: bytes},
+			)
+		}
+	}
+	typ := s.Dominant()
+	if typ != "BULKPUT" {
+		t.Fatalf("want dominant BULKPUT, got %q", typ)
+	}
+	if stable, _ := s.AutoTerm(typ, start.Add(12*time.Second), 0.075, 10*time.Second); stable {
+		t.Error("want ramp up to be unstable")
+	}
+	if stable, _ := s.AutoTerm(typ, start.Add(30*time.Second), 0.075, time.Minute); stable {
+		t.Error("want too short duration to be unstable")
+	}
+	stable, status := s.AutoTerm(typ, start.Add(50*time.Second), 0.075, 10*time.Second)
+	if !stable {
+		t.Fatal("want stable throughput")
+	}
+	if !strings.HasPrefix(status, "Throughput 20.0MiB/s") {
+		t.Errorf("unexpected status: %s", status)
+	}
+}
@@ -0,0 +1,394 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SpectraLogic/ds3_go_sdk/ds3"
+	"github.com/SpectraLogic/ds3_go_sdk/ds3/models"
+	"github.com/joshcarter/warp-ds3/pkg/generator"
+)
+
+// Mixed benchmarks a weighted mix of bulk puts, bulk gets, HEAD requests
+// and deletes against a shared pool of objects.
+type Mixed struct {
+	Common
+	CreateObjects int
+	BulkNum       int
+	Dist          *MixedDistribution
+	failed        createdObjects
+	persist       persistTracker
+}
+
+// MixedDistribution holds the operation distribution and the pool of
+// objects the operations act on.
+// Objects being read are never picked for deletion.
+type MixedDistribution struct {
+	// Distribution maps operation types to their weights.
+	Distribution map[string]float64
+
+	ops     []string
+	current int
+
+	mu      sync.Mutex
+	objects generator.Objects
+	busy    map[string]int
+}
+
+// Generate the operation sequence and validate the distribution.
+func (m *MixedDistribution) Generate() error {
+	const genOps = 1000
+	total := 0.0
+	for op, w := range m.Distribution {
+		if w < 0 {
+			return fmt.Errorf("negative distribution for %s", op)
+		}
+		total += w
+	}
+	if total <= 0 {
+		return errors.New("no operations in distribution")
+	}
+	// Sort for a reproducible sequence.
+	types := make([]string, 0, len(m.Distribution))
+	for op := range m.Distribution {
+		types = append(types, op)
+	}
+	sort.Strings(types)
+
+	m.ops = make([]string, 0, genOps)
+	for _, op := range types {
+		n := int(m.Distribution[op] / total * genOps)
+		for i := 0; i < n; i++ {
+			m.ops = append(m.ops, op)
+		}
+	}
+	if len(m.ops) == 0 {
+		return errors.New("distribution too uneven")
+	}
+	rng := rand.New(rand.NewSource(int64(len(m.ops))))
+	rng.Shuffle(len(m.ops), func(i, j int) {
+		m.ops[i], m.ops[j] = m.ops[j], m.ops[i]
+	})
+	m.busy = make(map[string]int)
+	return nil
+}
+
+// dominant returns the operation type with the highest weight.
+func (m *MixedDistribution) dominant() string {
+	best, bestW := "", -1.0
+	for op, w := range m.Distribution {
+		if w > bestW || (w == bestW && op < best) {
+			best, bestW = op, w
+		}
+	}
+	return best
+}
+
+// getOp returns the next operation to run.
+func (m *MixedDistribution) getOp() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	op := m.ops[m.current]
+	m.current = (m.current + 1) % len(m.ops)
+	return op
+}
+
+// add objects to the pool.
+func (m *MixedDistribution) add(objs ...generator.Object) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, obj := range objs {
+		obj.Reader = nil
+		m.objects = append(m.objects, obj)
+	}
+}
+
+// acquire n distinct random objects for reading.
+// Returns nil if the pool holds fewer than n objects.
+// Acquired objects must be released.
+func (m *MixedDistribution) acquire(rng *rand.Rand, n int) generator.Objects {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n > len(m.objects) {
+		return nil
+	}
+	res := make(generator.Objects, 0, n)
+	picked := make(map[int]struct{}, n)
+	for len(res) < n {
+		idx := rng.Intn(len(m.objects))
+		if _, ok := picked[idx]; ok {
+			continue
+		}
+		picked[idx] = struct{}{}
+		obj := m.objects[idx]
+		m.busy[obj.Name]++
+		res = append(res, obj)
+	}
+	return res
+}
+
+// release objects returned by acquire.
+func (m *MixedDistribution) release(objs generator.Objects) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, obj := range objs {
+		if m.busy[obj.Name]--; m.busy[obj.Name] <= 0 {
+			delete(m.busy, obj.Name)
+		}
+	}
+}
+
+// take removes a random object that is not being read from the pool.
+func (m *MixedDistribution) take(rng *rand.Rand) (generator.Object, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for tries := 0; tries < 10 && len(m.objects) > 0; tries++ {
+		idx := rng.Intn(len(m.objects))
+		obj := m.objects[idx]
+		if m.busy[obj.Name] > 0 {
+			continue
+		}
+		last := len(m.objects) - 1
+		m.objects[idx] = m.objects[last]
+		m.objects = m.objects[:last]
+		return obj, true
+	}
+	return generator.Object{}, false
+}
+
+// names returns the names of all objects in the pool.
+func (m *MixedDistribution) names() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return objectNames(m.objects)
+}
+
+// Prepare will create an empty bucket or delete any content already there
+// and upload the initial object pool using bulk put jobs.
+func (g *Mixed) Prepare(ctx context.Context) error {
+	if err := g.Dist.Generate(); err != nil {
+		return err
+	}
+	if err := g.createEmptyBucket(ctx); err != nil {
+		return err
+	}
+	objs, _, err := g.uploadBulkObjects(ctx, g.CreateObjects, g.BulkNum)
+	g.Dist.add(objs...)
+	return err
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+// Operations that cannot run because the object pool is too small
+// are replaced by bulk puts.
+func (g *Mixed) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var wg sync.WaitGroup
+	wg.Add(g.Concurrency)
+	c := g.newCollector()
+	if g.AutoTermDur > 0 {
+		ctx = c.AutoTerm(ctx, g.Dist.dominant(), g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
+	}
+
+	for i := 0; i < g.Concurrency; i++ {
+		go func(i int) {
+			rng := rand.New(rand.NewSource(int64(i)))
+			rcv := c.Receiver()
+			defer wg.Done()
+			done := ctx.Done()
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				var op Operation
+				var phases Operations
+				switch g.Dist.getOp() {
+				case "BULKGET":
+					if objs := g.Dist.acquire(rng, g.BulkNum); objs != nil {
+						op, phases = g.bulkGet(ctx, i, objs)
+						g.Dist.release(objs)
+						break
+					}
+					g.bulkPut(ctx, i, rcv)
+					continue
+				case http.MethodHead:
+					if objs := g.Dist.acquire(rng, 1); objs != nil {
+						op = g.head(i, objs[0])
+						g.Dist.release(objs)
+						break
+					}
+					g.bulkPut(ctx, i, rcv)
+					continue
+				case http.MethodDelete:
+					if obj, ok := g.Dist.take(rng); ok {
+						op = g.delete(i, obj)
+						break
+					}
+					g.bulkPut(ctx, i, rcv)
+					continue
+				default:
+					g.bulkPut(ctx, i, rcv)
+					continue
+				}
+				if ctx.Err() != nil {
+					// The benchmark ended while the operation was in progress.
+					return
+				}
+				rcv <- op
+				for _, phase := range phases {
+					rcv <- phase
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	g.persist.wait()
+	return c.Close(), nil
+}
+
+// bulkPut uploads BulkNum new objects in a bulk put job, adds them to the pool
+// and sends the operation to rcv.
+func (g *Mixed) bulkPut(ctx context.Context, thread int, rcv chan<- Operation) {
+	objs := make([]*generator.Object, g.BulkNum)
+	totalSize := int64(0)
+	for j := range objs {
+		objs[j] = g.Source().Object() // Create a new generator for each object
+		totalSize += objs[j].Size
+	}
+	op := Operation{
+		OpType:   "BULKPUT",
+		Thread:   uint16(thread),
+		Size:     totalSize,
+		File:     objs[0].Name,
+		ObjPerOp: len(objs),
+	}
+	client, cldone := g.Client()
+	op.Endpoint = g.endpoint(client)
+	op.Start = time.Now()
+	phases, err := g.putBulkObjects(ctx, client, objs, &op)
+	op.End = time.Now()
+	cldone()
+	switch {
+	case err == nil:
+		for _, obj := range objs {
+			g.Dist.add(*obj)
+		}
+	case op.JobID != "":
+		// Failed jobs may have left some objects behind.
+		for _, obj := range objs {
+			g.failed.add(obj.Name)
+		}
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			// The benchmark ended while the job was in progress.
+			return
+		}
+		g.Error("bulk put error: ", err)
+		op.Err = err.Error()
+	}
+	g.persist.send(&g.Common, client, op, rcv)
+	for _, phase := range phases {
+		rcv <- phase
+	}
+}
+
+// bulkGet downloads objs in a bulk get job.
+func (g *Mixed) bulkGet(ctx context.Context, thread int, objs generator.Objects) (Operation, Operations) {
+	names := objectNames(objs)
+	totalSize := int64(0)
+	for _, obj := range objs {
+		totalSize += obj.Size
+	}
+	op := Operation{
+		OpType:   "BULKGET",
+		Thread:   uint16(thread),
+		Size:     totalSize,
+		File:     names[0],
+		ObjPerOp: len(names),
+	}
+	client, cldone := g.Client()
+	op.Endpoint = g.endpoint(client)
+	op.Start = time.Now()
+	n, phases, err := g.getBulkObjects(ctx, client, names, &op)
+	op.End = time.Now()
+	cldone()
+	if ctx.Err() != nil {
+		return op, phases
+	}
+	if err != nil {
+		g.Error("bulk get error: ", err)
+		op.Err = err.Error()
+	}
+	if n != op.Size && op.Err == "" {
+		op.Err = fmt.Sprint("unexpected download size. want:", op.Size, ", got:", n)
+		g.Error(op.Err)
+	}
+	return op, phases
+}
+
+// head issues a HeadObject request for obj.
+func (g *Mixed) head(thread int, obj generator.Object) Operation {
+	op := Operation{
+		OpType:   http.MethodHead,
+		Thread:   uint16(thread),
+		Size:     0,
+		File:     obj.Name,
+		ObjPerOp: 1,
+	}
+	client, cldone := g.Client()
+	op.Endpoint = g.endpoint(client)
+	op.Start = time.Now()
+	err := g.headObject(client, obj)
+	op.End = time.Now()
+	cldone()
+	if err != nil {
+		g.Error("stat error: ", err)
+		op.Err = err.Error()
+	}
+	return op
+}
+
+// delete removes obj, which must already be taken from the pool.
+func (g *Mixed) delete(thread int, obj generator.Object) Operation {
+	op := Operation{
+		OpType:   http.MethodDelete,
+		Thread:   uint16(thread),
+		Size:     0,
+		File:     obj.Name,
+		ObjPerOp: 1,
+	}
+	client, cldone := g.Client()
+	op.Endpoint = g.endpoint(client)
+	op.Start = time.Now()
+	err := deleteObject(client, g.Bucket, obj.Name)
+	op.End = time.Now()
+	cldone()
+	if err != nil {
+		g.Error("delete error: ", err)
+		op.Err = err.Error()
+		g.failed.add(obj.Name)
+	}
+	return op
+}
+
+// deleteObject removes a single object.
+func deleteObject(client *ds3.Client, bucket, name string) error {
+	_, err := client.DeleteObject(models.NewDeleteObjectRequest(bucket, name))
+	return err
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+func (g *Mixed) Cleanup(ctx context.Context) {
+	names := append(g.Dist.names(), g.failed.get()...)
+	g.deleteObjects(ctx, names)
+}
@@ -0,0 +1,56 @@
+package bench
+
+import (
+	"math/rand"
+	"net/http"
+	"testing"
+
+	"github.com/joshcarter/warp-ds3/pkg/generator"
+)
+
+func TestMixedDistribution(t *testing.T) {
+	m := MixedDistribution{Distribution: map[string]float64{
+		"BULKPUT":         15,
+		"BULKGET":         45,
+		http.MethodHead:   30,
+		http.MethodDelete: 10,
+	}}
+	if err := m.Generate(); err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int)
+	for range m.ops {
+		got[m.getOp()]++
+	}
+	want := map[string]int{"BULKPUT": 150, "BULKGET": 450, http.MethodHead: 300, http.MethodDelete: 100}
+	for op, n := range want {
+		if got[op] != n {
+			t.Errorf("%s: want %d ops, got %d", op, n, got[op])
+		}
+	}
+	if m.dominant() != "BULKGET" {
+		t.Errorf("want dominant BULKGET, got %s", m.dominant())
+	}
+
+	rng := rand.New(rand.NewSource(0))
+	m.add(generator.Object{Name: "a"}, generator.Object{Name: "b"})
+	if objs := m.acquire(rng, 3); objs != nil {
+		t.Fatalf("acquired %d objects from pool of 2", len(objs))
+	}
+	objs := m.acquire(rng, 2)
+	if len(objs) != 2 || objs[0].Name == objs[1].Name {
+		t.Fatalf("want 2 distinct objects, got %v", objectNames(objs))
+	}
+	if _, ok := m.take(rng); ok {
+		t.Fatal("took an object being read")
+	}
+	m.release(objs)
+	for i := 0; i < 2; i++ {
+		if _, ok := m.take(rng); !ok {
+			t.Fatal("could not take object")
+		}
+	}
+	if len(m.names()) != 0 {
+		t.Fatalf("pool not empty: %v", m.names())
+	}
+}
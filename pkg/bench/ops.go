@@ -48,6 +48,12 @@ type Operation struct {
 	Thread    uint16     `json:"thread"`
 	ClientID  string     `json:"client_id"`
 	Endpoint  string     `json:"endpoint"`
+	// Time spent waiting for the server to make cache available.
+	CacheWait time.Duration `json:"cache_wait,omitempty"`
+	// DS3 job the operation belongs to.
+	JobID string `json:"job_id,omitempty"`
+	// Time the job was completed by the server, if waited for.
+	PersistEnd *time.Time `json:"persist_end,omitempty"`
 }
 
 type Collector struct {
@@ -57,6 +63,8 @@ type Collector struct {
 	opsMu sync.Mutex
 	rcv   chan Operation
 	rcvWg sync.WaitGroup
+	// Operations are also added here if set.
+	live *LiveOps
 }
 
 func NewCollector() *Collector {
@@ -71,6 +79,9 @@ func NewCollector() *Collector {
 			r.opsMu.Lock()
 			r.ops = append(r.ops, op)
 			r.opsMu.Unlock()
+			if r.live != nil {
+				r.live.Add(op)
+			}
 		}
 	}()
 	return r
@@ -92,7 +103,6 @@ func (c *Collector) AutoTerm(ctx context.Context, op string, threshold float64,
 		defer cancel()
 		ticker := time.NewTicker(time.Second)
 
-	checkloop:
 		for {
 			select {
 			case <-ctx.Done():
@@ -118,40 +128,54 @@ func (c *Collector) AutoTerm(ctx context.Context, op string, threshold float64,
 			if len(segs) < wantSamples {
 				continue
 			}
-			// Use last segment as our base.
-			mb, _, objs := segs[len(segs)-1].SpeedPerSec()
-			// Only use the segments we are interested in.
-			segs = segs[len(segs)-wantSamples : len(segs)-1]
-			for _, seg := range segs {
-				segMB, _, segObjs := seg.SpeedPerSec()
-				if mb > 0 {
-					if math.Abs(mb-segMB) > threshold*mb {
-						continue checkloop
-					}
-					continue
-				}
-				if math.Abs(objs-segObjs) > threshold*objs {
-					continue checkloop
-				}
+			mbs := make([]float64, len(segs))
+			objss := make([]float64, len(segs))
+			for i, seg := range segs {
+				mbs[i], _, objss[i] = seg.SpeedPerSec()
 			}
-			// All checks passed.
-			if mb > 0 {
-				console.Eraseline()
-				console.Printf("\rThroughput %0.01fMiB/s within %f%% for %v. Assuming stability. Terminating benchmark.\n",
-					mb, threshold*100,
-					segs[0].Duration().Round(time.Millisecond)*time.Duration(len(segs)+1))
-			} else {
-				console.Eraseline()
-				console.Printf("\rThroughput %0.01f objects/s within %f%% for %v. Assuming stability. Terminating benchmark.\n",
-					objs, threshold*100,
-					segs[0].Duration().Round(time.Millisecond)*time.Duration(len(segs)+1))
+			if !stableSpeed(mbs, objss, threshold, wantSamples) {
+				continue
 			}
+			// All checks passed.
+			console.Eraseline()
+			console.Printf("\r%s\n", autoTermStatus(mbs[len(mbs)-1], objss[len(objss)-1], threshold, segs[0].Duration()*time.Duration(wantSamples)))
 			return
 		}
 	}()
 	return ctx
 }
 
+// autoTermStatus describes the stable throughput that terminates a benchmark.
+func autoTermStatus(mb, objs, threshold float64, dur time.Duration) string {
+	if mb > 0 {
+		return fmt.Sprintf("Throughput %0.01fMiB/s within %f%% for %v. Assuming stability. Terminating benchmark.", mb, threshold*100, dur.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("Throughput %0.01f objects/s within %f%% for %v. Assuming stability. Terminating benchmark.", objs, threshold*100, dur.Round(time.Millisecond))
+}
+
+// stableSpeed returns whether the last wantSamples speeds are within threshold
+// of the last one, which is considered the current speed.
+// MiB/s are compared if the current speed has any, otherwise objects/s.
+func stableSpeed(mbs, objs []float64, threshold float64, wantSamples int) bool {
+	if len(mbs) < wantSamples || wantSamples < 1 {
+		return false
+	}
+	last := len(mbs) - 1
+	mb, obj := mbs[last], objs[last]
+	for i := len(mbs) - wantSamples; i < last; i++ {
+		if mb > 0 {
+			if math.Abs(mb-mbs[i]) > threshold*mb {
+				return false
+			}
+			continue
+		}
+		if math.Abs(obj-objs[i]) > threshold*obj {
+			return false
+		}
+	}
+	return true
+}
+
 func (c *Collector) Receiver() chan<- Operation {
 	return c.rcv
 }
@@ -283,6 +307,15 @@ func (o Operation) Aggregate(s *Segment) (done bool) {
 	return done
 }
 
+// IsJobPhase returns whether the operation records a phase of a bulk job.
+func (o Operation) IsJobPhase() bool {
+	switch o.OpType {
+	case OpJobCreate, OpChunkWait, OpBlobPut, OpBlobGet:
+		return true
+	}
+	return false
+}
+
 // TTFB returns the time to first byte or 0 if nothing was recorded.
 func (o Operation) TTFB() time.Duration {
 	if o.FirstByte == nil {
@@ -291,6 +324,14 @@ func (o Operation) TTFB() time.Duration {
 	return o.FirstByte.Sub(o.Start)
 }
 
+// PersistDuration returns the time until the job completed or 0 if nothing was recorded.
+func (o Operation) PersistDuration() time.Duration {
+	if o.PersistEnd == nil {
+		return 0
+	}
+	return o.PersistEnd.Sub(o.Start)
+}
+
 // SortByStartTime will sort the operations by start time.
 // Earliest operations first.
 func (o Operations) SortByStartTime() {
@@ -393,6 +434,17 @@ func (o Operations) FilterByHasTTFB(hasTTFB bool) Operations {
 	return dst
 }
 
+// FilterByJobPhase returns operations that are or are not bulk job phases.
+func (o Operations) FilterByJobPhase(isPhase bool) Operations {
+	dst := make(Operations, 0, len(o))
+	for _, o := range o {
+		if o.IsJobPhase() == isPhase {
+			dst = append(dst, o)
+		}
+	}
+	return dst
+}
+
 // FilterInsideRange returns operations that are inside the specified time range.
 // Operations starting before start or ending after end are discarded.
 func (o Operations) FilterInsideRange(start, end time.Time) Operations {
@@ -633,6 +685,36 @@ func (o Operations) AvgDuration() time.Duration {
 	return total / time.Duration(len(o))
 }
 
+// AvgCacheWait returns the average time operations waited for cache.
+func (o Operations) AvgCacheWait() time.Duration {
+	if len(o) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, op := range o {
+		total += op.CacheWait
+	}
+	return total / time.Duration(len(o))
+}
+
+// PersistLatency returns the average ingest and persist durations
+// of the operations that recorded job completion.
+// n is the number of operations included.
+func (o Operations) PersistLatency() (ingest, persist time.Duration, n int) {
+	for _, op := range o {
+		if op.PersistEnd == nil {
+			continue
+		}
+		ingest += op.Duration()
+		persist += op.PersistDuration()
+		n++
+	}
+	if n == 0 {
+		return 0, 0, 0
+	}
+	return ingest / time.Duration(n), persist / time.Duration(n), n
+}
+
 // StdDev returns the standard deviation.
 func (o Operations) StdDev() time.Duration {
 	if len(o) <= 1 {
@@ -1034,16 +1116,19 @@ func (o Operations) FilterErrors() Operations {
 // The comment, if any, is written at the end of the file, each line prefixed with '# '.
 func (o Operations) CSV(w io.Writer, comment string) error {
 	bw := bufio.NewWriter(w)
-	_, err := bw.WriteString("idx\tthread\top\tclient_id\tn_objects\tbytes\tendpoint\tfile\terror\tstart\tfirst_byte\tend\tduration_ns\n")
+	_, err := bw.WriteString("idx\tthread\top\tclient_id\tn_objects\tbytes\tendpoint\tfile\terror\tstart\tfirst_byte\tend\tduration_ns\tcache_wait_ns\tjob_id\tpersist_end\n")
 	if err != nil {
 		return err
 	}
 	for i, op := range o {
-		var ttfb string
+		var ttfb, persistEnd string
 		if op.FirstByte != nil {
 			ttfb = op.FirstByte.Format(time.RFC3339Nano)
 		}
-		_, err := fmt.Fprintf(bw, "%d\t%d\t%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n", i, op.Thread, op.OpType, op.ClientID, op.ObjPerOp, op.Size, csvEscapeString(op.Endpoint), op.File, csvEscapeString(op.Err), op.Start.Format(time.RFC3339Nano), ttfb, op.End.Format(time.RFC3339Nano), op.End.Sub(op.Start)/time.Nanosecond)
+		if op.PersistEnd != nil {
+			persistEnd = op.PersistEnd.Format(time.RFC3339Nano)
+		}
+		_, err := fmt.Fprintf(bw, "%d\t%d\t%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\n", i, op.Thread, op.OpType, op.ClientID, op.ObjPerOp, op.Size, csvEscapeString(op.Endpoint), op.File, csvEscapeString(op.Err), op.Start.Format(time.RFC3339Nano), ttfb, op.End.Format(time.RFC3339Nano), op.End.Sub(op.Start)/time.Nanosecond, op.CacheWait/time.Nanosecond, op.JobID, persistEnd)
 		if err != nil {
 			return err
 		}
@@ -1155,20 +1240,43 @@ func OperationsFromCSV(r io.Reader, analyzeOnly bool, offset, limit int, log fun
 		if idx, ok := fieldIdx["client_id"]; ok {
 			clientID = values[idx]
 		}
+		var cacheWait time.Duration
+		if idx, ok := fieldIdx["cache_wait_ns"]; ok {
+			ns, err := strconv.ParseInt(values[idx], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			cacheWait = time.Duration(ns)
+		}
+		var jobID string
+		if idx, ok := fieldIdx["job_id"]; ok {
+			jobID = values[idx]
+		}
+		var persistEnd *time.Time
+		if idx, ok := fieldIdx["persist_end"]; ok && values[idx] != "" {
+			t, err := time.Parse(time.RFC3339Nano, values[idx])
+			if err != nil {
+				return nil, err
+			}
+			persistEnd = &t
+		}
 		file := fileMap(values[fieldIdx["file"]])
 
 		ops = append(ops, Operation{
-			OpType:    values[fieldIdx["op"]],
-			ObjPerOp:  int(objs),
-			Start:     start,
-			FirstByte: ttfb,
-			End:       end,
-			Err:       values[fieldIdx["error"]],
-			Size:      size,
-			File:      file,
-			Thread:    uint16(thread),
-			Endpoint:  endpoint,
-			ClientID:  getClient(clientID),
+			OpType:     values[fieldIdx["op"]],
+			ObjPerOp:   int(objs),
+			Start:      start,
+			FirstByte:  ttfb,
+			End:        end,
+			Err:        values[fieldIdx["error"]],
+			Size:       size,
+			File:       file,
+			Thread:     uint16(thread),
+			Endpoint:   endpoint,
+			ClientID:   getClient(clientID),
+			CacheWait:  cacheWait,
+			JobID:      jobID,
+			PersistEnd: persistEnd,
 		})
 		if log != nil && len(ops)%1000000 == 0 {
 			console.Eraseline()
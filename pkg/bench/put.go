@@ -19,15 +19,24 @@ package bench
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/SpectraLogic/ds3_go_sdk/ds3"
+	"github.com/SpectraLogic/ds3_go_sdk/ds3/models"
+	"github.com/SpectraLogic/ds3_go_sdk/helpers"
+	"github.com/joshcarter/warp-ds3/pkg/generator"
+	"github.com/minio/pkg/console"
 )
 
 // Put benchmarks upload speed.
 type Put struct {
 	Common
 	prefixes map[string]struct{}
+	created  createdObjects
 }
 
 // Prepare will create an empty bucket ot delete any content already there.
@@ -40,7 +49,7 @@ func (u *Put) Prepare(ctx context.Context) error {
 func (u *Put) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
 	var wg sync.WaitGroup
 	wg.Add(u.Concurrency)
-	c := NewCollector()
+	c := u.newCollector()
 	if u.AutoTermDur > 0 {
 		ctx = c.AutoTerm(ctx, http.MethodPut, u.AutoTermScale, autoTermCheck, autoTermSamples, u.AutoTermDur)
 	}
@@ -52,7 +61,6 @@ func (u *Put) Start(ctx context.Context, wait chan struct{}) (Operations, error)
 		go func(i int) {
 			rcv := c.Receiver()
 			defer wg.Done()
-			opts := u.PutOpts
 			done := ctx.Done()
 
 			<-wait
@@ -63,35 +71,25 @@ func (u *Put) Start(ctx context.Context, wait chan struct{}) (Operations, error)
 				default:
 				}
 				obj := src.Object()
-				opts.ContentType = obj.ContentType
-				// client, cldone := u.Client()
-				_, cldone := u.Client()
+				client, cldone := u.Client()
 				op := Operation{
 					OpType:   http.MethodPut,
 					Thread:   uint16(i),
 					Size:     obj.Size,
 					File:     obj.Name,
 					ObjPerOp: 1,
-					Endpoint: u.Endpoint,
+					Endpoint: u.endpoint(client),
 				}
 				op.Start = time.Now()
-				// TODO
-				//res, err := client.PutObject(nonTerm, u.Bucket, obj.Name, obj.Reader, obj.Size, opts)
-				//op.End = time.Now()
-				//if err != nil {
-				//	u.Error("upload error: ", err)
-				//	op.Err = err.Error()
-				//}
-				//obj.VersionID = res.VersionID
-				//
-				//if res.Size != obj.Size && op.Err == "" {
-				//	err := fmt.Sprint("short upload. want:", obj.Size, ", got:", res.Size)
-				//	if op.Err == "" {
-				//		op.Err = err
-				//	}
-				//	u.Error(err)
-				//}
-				//op.Size = res.Size
+				n, err := putObject(client, u.Bucket, obj)
+				op.End = time.Now()
+				if err != nil {
+					u.Error("upload error: ", err)
+					op.Err = err.Error()
+				} else {
+					u.created.add(obj.Name)
+				}
+				op.Size = n
 				cldone()
 				rcv <- op
 			}
@@ -103,4 +101,87 @@ func (u *Put) Start(ctx context.Context, wait chan struct{}) (Operations, error)
 
 // Cleanup deletes everything uploaded to the bucket.
 func (u *Put) Cleanup(ctx context.Context) {
+	u.deleteObjects(ctx, u.created.get())
+}
+
+// putObject uploads obj with a naked put and returns the number of bytes sent.
+// Uploads sending fewer bytes than the object size return an error.
+func putObject(client *ds3.Client, bucket string, obj *generator.Object) (int64, error) {
+	// Count what is actually sent, since DS3 does not return the stored size.
+	cr := &countingReader{r: obj.Reader}
+	putObjRequest := models.NewPutObjectRequest(bucket, obj.Name, helpers.NewIoReaderWithSizeDecorator(cr, obj.Size))
+	if _, err := client.PutObject(putObjRequest); err != nil {
+		return cr.n, err
+	}
+	if cr.n != obj.Size {
+		return cr.n, fmt.Errorf("short upload. want: %d, got: %d", obj.Size, cr.n)
+	}
+	return cr.n, nil
+}
+
+// uploadObjects uploads n objects with naked puts, running Concurrency uploads at once.
+// Each uploader uses its own source and thereby its own prefix.
+// The uploaded objects are returned without readers.
+func (c *Common) uploadObjects(ctx context.Context, n int) (generator.Objects, error) {
+	src := c.Source()
+	console.Eraseline()
+	console.Info("\rUploading ", n, " objects of ", src.String())
+
+	left := make(chan struct{}, n)
+	for i := 0; i < n; i++ {
+		left <- struct{}{}
+	}
+	close(left)
+
+	var wg sync.WaitGroup
+	var groupErr error
+	var mu sync.Mutex
+	uploaded := make(generator.Objects, 0, n)
+	wg.Add(c.Concurrency)
+	for i := 0; i < c.Concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			src := c.Source()
+			for range left {
+				select {
+				case <-ctx.Done():
+					return
+				default:
+				}
+				obj := src.Object()
+				client, cldone := c.Client()
+				_, err := putObject(client, c.Bucket, obj)
+				cldone()
+				if err != nil {
+					err := fmt.Errorf("upload error: %w", err)
+					c.Error(err)
+					mu.Lock()
+					if groupErr == nil {
+						groupErr = err
+					}
+					mu.Unlock()
+					return
+				}
+				obj.Reader = nil
+				mu.Lock()
+				uploaded = append(uploaded, *obj)
+				c.prepareProgress(float64(len(uploaded)) / float64(n))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return uploaded, groupErr
+}
+
+// countingReader counts the bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (n int, err error) {
+	n, err = c.r.Read(p)
+	c.n += int64(n)
+	return n, err
 }
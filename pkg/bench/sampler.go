@@ -0,0 +1,26 @@
+package bench
+
+import (
+	"context"
+	"time"
+)
+
+// pollSamples calls sample every interval until ctx is canceled and returns all samples.
+// If sampled is not nil it is called with every sample as it is taken.
+func pollSamples[T any](ctx context.Context, interval time.Duration, sample func() T, sampled func(T)) []T {
+	var samples []T
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s := sample()
+		samples = append(samples, s)
+		if sampled != nil {
+			sampled(s)
+		}
+		select {
+		case <-ctx.Done():
+			return samples
+		case <-ticker.C:
+		}
+	}
+}
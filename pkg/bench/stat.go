@@ -0,0 +1,139 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/SpectraLogic/ds3_go_sdk/ds3"
+	"github.com/SpectraLogic/ds3_go_sdk/ds3/models"
+	"github.com/joshcarter/warp-ds3/pkg/generator"
+)
+
+// Stat benchmarks object metadata requests.
+type Stat struct {
+	Common
+	CreateObjects int
+	// Head and Details select whether HeadObject and
+	// GetObjectDetailsSpectraS3 requests are issued.
+	// When both are set, workers alternate between them.
+	Head    bool
+	Details bool
+	objects generator.Objects
+}
+
+// Prepare will create an empty bucket or delete any content already there
+// and upload a number of objects.
+func (g *Stat) Prepare(ctx context.Context) error {
+	if err := g.createEmptyBucket(ctx); err != nil {
+		return err
+	}
+	var err error
+	g.objects, err = g.uploadObjects(ctx, g.CreateObjects)
+	return err
+}
+
+// Start will execute the main benchmark.
+// Operations should begin executing when the start channel is closed.
+func (g *Stat) Start(ctx context.Context, wait chan struct{}) (Operations, error) {
+	var wg sync.WaitGroup
+	wg.Add(g.Concurrency)
+	c := g.newCollector()
+	if g.AutoTermDur > 0 {
+		opType := http.MethodHead
+		if !g.Head {
+			opType = "DETAILS"
+		}
+		ctx = c.AutoTerm(ctx, opType, g.AutoTermScale, autoTermCheck, autoTermSamples, g.AutoTermDur)
+	}
+
+	for i := 0; i < g.Concurrency; i++ {
+		go func(i int) {
+			rng := rand.New(rand.NewSource(int64(i)))
+			rcv := c.Receiver()
+			defer wg.Done()
+			done := ctx.Done()
+			head := g.Head
+
+			<-wait
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				obj := g.objects[rng.Intn(len(g.objects))]
+				op := Operation{
+					OpType:   http.MethodHead,
+					Thread:   uint16(i),
+					Size:     0,
+					File:     obj.Name,
+					ObjPerOp: 1,
+				}
+				if !head {
+					op.OpType = "DETAILS"
+				}
+				client, cldone := g.Client()
+				op.Endpoint = g.endpoint(client)
+				op.Start = time.Now()
+				var err error
+				if head {
+					err = g.headObject(client, obj)
+				} else {
+					err = g.objectDetails(client, obj)
+				}
+				op.End = time.Now()
+				cldone()
+				if err != nil {
+					g.Error("stat error: ", err)
+					op.Err = err.Error()
+				}
+				rcv <- op
+				if g.Head && g.Details {
+					head = !head
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	return c.Close(), nil
+}
+
+// headObject issues a HeadObject request and checks the returned size.
+func (c *Common) headObject(client *ds3.Client, obj generator.Object) error {
+	resp, err := client.HeadObject(models.NewHeadObjectRequest(c.Bucket, obj.Name))
+	if err != nil {
+		return err
+	}
+	if resp.Headers == nil {
+		return nil
+	}
+	if cl := resp.Headers.Get("Content-Length"); cl != "" {
+		size, err := strconv.ParseInt(cl, 10, 64)
+		if err == nil && size != obj.Size {
+			return fmt.Errorf("unexpected size of %s. want: %d, got: %d", obj.Name, obj.Size, size)
+		}
+	}
+	return nil
+}
+
+// objectDetails issues a GetObjectDetailsSpectraS3 request.
+func (g *Stat) objectDetails(client *ds3.Client, obj generator.Object) error {
+	resp, err := client.GetObjectDetailsSpectraS3(models.NewGetObjectDetailsSpectraS3Request(obj.Name, g.Bucket))
+	if err != nil {
+		return err
+	}
+	if resp.S3Object.Name != nil && *resp.S3Object.Name != obj.Name {
+		return fmt.Errorf("unexpected object details. want: %s, got: %s", obj.Name, *resp.S3Object.Name)
+	}
+	return nil
+}
+
+// Cleanup deletes everything uploaded to the bucket.
+func (g *Stat) Cleanup(ctx context.Context) {
+	g.deleteObjects(ctx, objectNames(g.objects))
+}